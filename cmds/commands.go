@@ -5,20 +5,32 @@ package cmds
 
 import (
 	"github.com/flexer2006/simpleArchiver-golang/internal/application"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcList"
 	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
 	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcUnpack"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcVerify"
 )
 
 // InitCommands registers subcommands with the root command and wraps the initialization
 // process with panic recovery. This function:
 //   - Adds vlcPack.VlcPackCmd as a subcommand for packing operations
 //   - Adds vlcUnpack.VlcUnpackCmd as a subcommand for unpacking operations
+//   - Adds vlcPack.PackCmd and vlcUnpack.UnpackCmd for multi-file archives
+//   - Adds vlcList.ListCmd for listing archive contents
+//   - Adds vlcVerify.VerifyCmd for checking archives for damage
 //   - Uses application.HandlePanic to ensure safe command registration
 //
-// Should be called during application startup before executing the root command.
+// This is the only place commands are added to the root command. Should be called
+// during application startup before executing the root command.
 func InitCommands() {
 	application.HandlePanic(func() {
-		application.RootCmd.AddCommand(vlcPack.VlcPackCmd)
-		application.RootCmd.AddCommand(vlcUnpack.VlcUnpackCmd)
+		application.RootCmd.AddCommand(
+			vlcPack.VlcPackCmd,
+			vlcUnpack.VlcUnpackCmd,
+			vlcPack.PackCmd,
+			vlcUnpack.UnpackCmd,
+			vlcList.ListCmd,
+			vlcVerify.VerifyCmd,
+		)
 	})
 }
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"errors"
+	"os"
+)
+
+// StdioPath is the path argument standing for standard input, or standard
+// output where an output path is expected, as with gzip and tar.
+const StdioPath = "-"
+
+// ErrTerminalOutput is returned when packed data would be written to a terminal,
+// where it is unreadable and may upset the terminal.
+var ErrTerminalOutput = errors.New("refusing to write packed data to a terminal; redirect standard output")
+
+// InputPath returns the input path given as the first argument. When there is
+// none and standard input is a pipe or file rather than a terminal, StdioPath is
+// returned so the command reads standard input.
+// Parameters:
+//   - args: []string - The command-line arguments
+//
+// Returns:
+//   - string: The input path, possibly StdioPath
+//   - error: ErrEmptyPath if no input is available
+func InputPath(args []string) (string, error) {
+	if len(args) > 0 && args[0] != "" {
+		return args[0], nil
+	}
+	if len(args) == 0 && !IsTerminal(os.Stdin) {
+		return StdioPath, nil
+	}
+	return "", ErrEmptyPath
+}
@@ -0,0 +1,24 @@
+package application
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// IsTerminal reports whether f is a terminal, as opposed to a pipe, a regular
+// file or another character device such as /dev/null. Only a terminal answers
+// the TCGETS ioctl, which reads its settings.
+func IsTerminal(f *os.File) bool {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var termios syscall.Termios
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	})
+	return err == nil && errno == 0
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsTerminalRejectsOtherFiles(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	regular, err := os.Create(t.TempDir() + "/file")
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	defer regular.Close()
+
+	tests := []struct {
+		name string
+		file *os.File
+	}{
+		{name: "character device", file: devNull},
+		{name: "pipe", file: w},
+		{name: "regular file", file: regular},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if IsTerminal(tt.file) {
+				t.Errorf("IsTerminal(%s) = true, want false", tt.file.Name())
+			}
+		})
+	}
+}
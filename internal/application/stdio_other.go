@@ -0,0 +1,13 @@
+//go:build !linux
+
+package application
+
+import "os"
+
+// IsTerminal reports whether f is a character device such as a terminal, as
+// opposed to a pipe or regular file. Terminals are only told apart from other
+// character devices, such as /dev/null, on Linux.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,289 @@
+// Package adaptiveHuffman implements the adaptive Huffman code of Faller, Gallager
+// and Knuth (FGK). Encoder and decoder start from the same tree, holding only the
+// not-yet-transmitted (NYT) node, and update it identically after every symbol, so
+// no code table is stored and data is encoded in a single pass as it is read.
+//
+// A byte seen for the first time is written as the code of the NYT node followed
+// by its value in symbolBits bits; later occurrences use the code of its leaf. The
+// stream ends with the pseudo-symbol endOfStream, sent the same way as a new byte,
+// and the final byte is padded with zero bits.
+package adaptiveHuffman
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+const (
+	// endOfStream is the pseudo-symbol closing every stream, after the 256 byte values.
+	endOfStream = 256
+
+	// symbolBits is the width of the literal symbol following the NYT code: enough
+	// for every byte value and endOfStream.
+	symbolBits = 9
+
+	// maxNodes is the number of nodes in a tree holding a leaf for every byte value
+	// and endOfStream, their parents and the NYT node.
+	maxNodes = 2*(endOfStream+1) + 1
+
+	// nytSymbol marks the NYT leaf.
+	nytSymbol = -1
+)
+
+// node is a node of the tree. Leaves have no children and carry a symbol.
+type node struct {
+	weight      uint64
+	parent      int // -1 for the root
+	left, right int // -1 for leaves
+	symbol      int
+}
+
+// tree is the adaptive Huffman tree. Nodes are stored by their FGK node number, so
+// the root is the last node and weights never decrease along the array (the
+// sibling property). New nodes are taken from below the NYT node.
+type tree struct {
+	nodes [maxNodes]node
+	leaf  [endOfStream + 1]int // index of the leaf of every symbol seen so far, else -1
+	nyt   int
+	path  []bool // scratch space for writeCode
+}
+
+// Encode reads src as it arrives, encodes every byte with the adaptive Huffman
+// tree and writes the codes, followed by the end-of-stream symbol, to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	in := bufio.NewReader(src)
+	out := bufio.NewWriter(dst)
+	bw := chunks.NewBitWriter(out)
+	t := newTree()
+
+	for {
+		b, err := in.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		if err := t.encode(bw, int(b)); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+	}
+
+	if err := t.encode(bw, endOfStream); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, rebuilding the tree as it goes, and
+// writes the decoded bytes to dst until the end-of-stream symbol. Only the zero
+// bits padding the final byte may follow it. For a chunks.LimitedWriter,
+// decoding stops with chunks.ErrLimit at the first symbol past the limit.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the data is truncated or corrupt, or if writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+	bits := chunks.NewBitReader(br)
+	out := bufio.NewWriter(dst)
+	t := newTree()
+	limit := chunks.Remaining(dst)
+
+	for decoded := 0; ; decoded++ {
+		symbol, err := t.decode(bits)
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return fmt.Errorf("symbol %d: %w", decoded, err)
+		}
+		if symbol == endOfStream {
+			break
+		}
+		if int64(decoded) >= limit {
+			return fmt.Errorf("symbol %d: %w", decoded, chunks.ErrLimit)
+		}
+		if err := out.WriteByte(byte(symbol)); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+	}
+
+	if padding := bits.Buffered(); padding >= chunks.ChunkSize {
+		return fmt.Errorf("unexpected %d trailing bits after last symbol", padding)
+	}
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last symbol")
+	}
+
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// newTree returns the initial tree, whose root is the NYT node.
+func newTree() *tree {
+	t := &tree{nyt: maxNodes - 1}
+	for i := range t.leaf {
+		t.leaf[i] = -1
+	}
+	t.nodes[t.nyt] = node{parent: -1, left: -1, right: -1, symbol: nytSymbol}
+	return t
+}
+
+// encode writes the code of symbol to bw, as the NYT code and the literal symbol
+// if it is new, and updates the tree.
+func (t *tree) encode(bw *chunks.BitWriter, symbol int) error {
+	q := t.leaf[symbol]
+	if q < 0 {
+		if err := t.writeCode(bw, t.nyt); err != nil {
+			return err
+		}
+		if err := bw.WriteBits(uint64(symbol), symbolBits); err != nil {
+			return err
+		}
+		q = t.add(symbol)
+	} else if err := t.writeCode(bw, q); err != nil {
+		return err
+	}
+
+	t.update(q)
+	return nil
+}
+
+// decode reads the code of one symbol from bits, and the literal symbol after the
+// NYT code, updates the tree and returns the symbol.
+func (t *tree) decode(bits *chunks.BitReader) (int, error) {
+	i := maxNodes - 1
+	for t.nodes[i].left >= 0 {
+		bit, err := bits.ReadBit()
+		if err != nil {
+			return 0, err
+		}
+		if bit == 1 {
+			i = t.nodes[i].right
+		} else {
+			i = t.nodes[i].left
+		}
+	}
+
+	symbol := t.nodes[i].symbol
+	if i == t.nyt {
+		value, err := bits.ReadBits(symbolBits)
+		if err != nil {
+			return 0, err
+		}
+		symbol = int(value)
+		if symbol > endOfStream || t.leaf[symbol] >= 0 {
+			return 0, fmt.Errorf("invalid new symbol %d", symbol)
+		}
+		i = t.add(symbol)
+	}
+
+	t.update(i)
+	return symbol, nil
+}
+
+// writeCode writes the code of the node at i, the path to it from the root with
+// right branches as 1 bits, to bw.
+func (t *tree) writeCode(bw *chunks.BitWriter, i int) error {
+	t.path = t.path[:0]
+	for p := t.nodes[i].parent; p >= 0; i, p = p, t.nodes[p].parent {
+		t.path = append(t.path, t.nodes[p].right == i)
+	}
+
+	// The path was collected leaf first; write it root first, 64 bits at a time.
+	var bits uint64
+	var n uint
+	for j := len(t.path) - 1; j >= 0; j-- {
+		bits <<= 1
+		if t.path[j] {
+			bits |= 1
+		}
+		if n++; n == 64 {
+			if err := bw.WriteBits(bits, n); err != nil {
+				return err
+			}
+			bits, n = 0, 0
+		}
+	}
+	return bw.WriteBits(bits, n)
+}
+
+// add splits the NYT node into a new NYT node and a leaf for symbol, both of
+// weight zero, and returns the index of the leaf.
+func (t *tree) add(symbol int) int {
+	parent := t.nyt
+	leaf, nyt := parent-1, parent-2
+
+	t.nodes[leaf] = node{parent: parent, left: -1, right: -1, symbol: symbol}
+	t.nodes[nyt] = node{parent: parent, left: -1, right: -1, symbol: nytSymbol}
+	t.nodes[parent].left, t.nodes[parent].right = nyt, leaf
+	t.leaf[symbol] = leaf
+	t.nyt = nyt
+	return leaf
+}
+
+// update increments the weight of the node at q and of its ancestors. Each node
+// is first swapped with the highest-numbered node of the same weight, unless that
+// is its parent, which keeps the sibling property and so the tree a Huffman tree.
+func (t *tree) update(q int) {
+	for q >= 0 {
+		leader := q
+		for leader+1 < maxNodes && t.nodes[leader+1].weight == t.nodes[q].weight {
+			leader++
+		}
+		if leader != q && leader != t.nodes[q].parent {
+			t.swap(q, leader)
+			q = leader
+		}
+
+		t.nodes[q].weight++
+		q = t.nodes[q].parent
+	}
+}
+
+// swap exchanges the subtrees at positions a and b, neither an ancestor of the
+// other. Each position keeps its parent, so the subtrees trade places in the tree.
+func (t *tree) swap(a, b int) {
+	parentA, parentB := t.nodes[a].parent, t.nodes[b].parent
+	t.nodes[a], t.nodes[b] = t.nodes[b], t.nodes[a]
+	t.nodes[a].parent, t.nodes[b].parent = parentA, parentB
+	t.adopt(a)
+	t.adopt(b)
+}
+
+// adopt points the children of the node at i, or the index of its symbol, back to i.
+func (t *tree) adopt(i int) {
+	n := t.nodes[i]
+	switch {
+	case n.left >= 0:
+		t.nodes[n.left].parent = i
+		t.nodes[n.right].parent = i
+	case n.symbol == nytSymbol:
+		t.nyt = i
+	default:
+		t.leaf[n.symbol] = i
+	}
+}
@@ -0,0 +1,143 @@
+package adaptiveHuffman
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	skewed := make([]byte, 100000)
+	for i := range skewed {
+		// Geometric-ish distribution exercising many swaps and deep trees.
+		skewed[i] = byte(rng.ExpFloat64() * 8)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "single symbol", data: []byte("aaaaaaa")},
+		{name: "text", data: []byte("abracadabra, said the wizard\n")},
+		{name: "binary", data: []byte{0, 255, 0, 1, 2, 3, 255, 255, 128}},
+		{name: "every byte twice", data: append(all, all...)},
+		{name: "skewed", data: skewed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestEncodeCompressesText(t *testing.T) {
+	data := bytes.Repeat([]byte("an adaptive code learns the statistics as it goes; "), 200)
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if encoded.Len() > len(data)*3/4 {
+		t.Errorf("Encode() wrote %d bytes for %d bytes of text, want at most %d", encoded.Len(), len(data), len(data)*3/4)
+	}
+}
+
+func TestDecodeRejectsDamagedStreams(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader([]byte("truncate me, please"))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	stream := encoded.Bytes()
+
+	err := Decode(io.Discard, bytes.NewReader(stream[:len(stream)-2]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode() of a truncated stream: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Decode(io.Discard, bytes.NewReader(append(bytes.Clone(stream), 0))); err == nil {
+		t.Error("Decode() with trailing data succeeded, want error")
+	}
+}
+
+func TestUpdateKeepsSiblingProperty(t *testing.T) {
+	tr := newTree()
+	rng := rand.New(rand.NewSource(2))
+	for n := 0; n < 5000; n++ {
+		symbol := int(rng.ExpFloat64() * 20)
+		if symbol > 255 {
+			symbol = 255
+		}
+		q := tr.leaf[symbol]
+		if q < 0 {
+			q = tr.add(symbol)
+		}
+		tr.update(q)
+
+		for i := tr.nyt; i < maxNodes; i++ {
+			nd := tr.nodes[i]
+			if i+1 < maxNodes && nd.weight > tr.nodes[i+1].weight {
+				t.Fatalf("after %d symbols: node %d weighs %d, more than node %d", n+1, i, nd.weight, i+1)
+			}
+			if nd.left >= 0 && nd.weight != tr.nodes[nd.left].weight+tr.nodes[nd.right].weight {
+				t.Fatalf("after %d symbols: node %d weighs %d, not the sum of its children", n+1, i, nd.weight)
+			}
+		}
+	}
+}
+
+func TestDecodeStopsAtLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 1<<20)
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr error
+	}{
+		{name: "limit at length", limit: int64(len(data))},
+		{name: "limit below length", limit: int64(len(data)) - 1, wantErr: chunks.ErrLimit},
+		{name: "zero limit", limit: 0, wantErr: chunks.ErrLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded bytes.Buffer
+			src := bytes.NewReader(encoded.Bytes())
+			err := Decode(chunks.LimitWriter(&decoded, tt.limit), src)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if int64(decoded.Len()) > tt.limit {
+				t.Errorf("Decode() wrote %d bytes, want at most %d", decoded.Len(), tt.limit)
+			}
+			// Decoding stops at the limit instead of reading the rest of the stream.
+			if tt.limit == 0 && src.Len() == 0 {
+				t.Errorf("Decode() read all %d encoded bytes, want it to stop early", encoded.Len())
+			}
+			if err == nil && !bytes.Equal(decoded.Bytes(), data) {
+				t.Errorf("Decode() returned %d bytes, want %d matching bytes", decoded.Len(), len(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package bwt implements a block-sorting codec in the style of bzip2. Every block
+// goes through the Burrows-Wheeler transform, computed from a suffix array, which
+// groups bytes by the context following them; move-to-front coding then turns the
+// resulting runs of equal bytes into runs of zeros, which are run-length encoded,
+// and the remaining symbols are Huffman coded and decoded with a
+// decodingTree.LookupTable. On text this beats any fixed or order-0 table.
+//
+// An encoded stream is a sequence of blocks, each starting with its length as a
+// uvarint, and ends with a length of zero. A block continues with the code
+// lengths of its symbols (see table.MarshalLengths), the row of the end marker
+// in the transform and the size of the packed symbols as uvarints, and the packed
+// symbols padded with zero bits to a whole byte.
+package bwt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/huffman"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+// BlockSize is the largest block sorted at once. It matches container.BlockSize,
+// so every block of a packed stream is sorted whole and no larger buffer is
+// ever needed.
+const BlockSize = container.BlockSize
+
+// Encode reads src a block at a time, transforms and codes every block and writes
+// the blocks, followed by a zero length, to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading, packing or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	out := bufio.NewWriter(dst)
+	for {
+		// The buffer grows with the input, up to BlockSize.
+		data, err := io.ReadAll(io.LimitReader(src, BlockSize))
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		if len(data) == 0 {
+			break
+		}
+
+		block, err := encodeBlock(data)
+		if err != nil {
+			return fmt.Errorf("encode block: %w", err)
+		}
+		_, _ = out.Write(block)
+		if len(data) < BlockSize {
+			break
+		}
+	}
+
+	_, _ = out.Write(binary.AppendUvarint(nil, 0))
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src and writes every decoded block to dst.
+// Nothing may follow the final zero length.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the data is truncated or corrupt, or if writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+	for blocks := 0; ; blocks++ {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fmt.Errorf("block %d: invalid length: %w", blocks, truncated(err))
+		}
+		if size == 0 {
+			break
+		}
+		if size > BlockSize {
+			return fmt.Errorf("block %d: length %d exceeds %d", blocks, size, BlockSize)
+		}
+
+		data, err := decodeBlock(br, int(size))
+		if err != nil {
+			return fmt.Errorf("block %d: %w", blocks, err)
+		}
+		if _, err := dst.Write(data); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+	}
+
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last block")
+	}
+	return nil
+}
+
+// encodeBlock returns the encoded form of a non-empty block.
+func encodeBlock(data []byte) ([]byte, error) {
+	last, primary := transform(data)
+	symbols := moveToFront(last)
+
+	freqs := make(table.Frequencies, alphabetSize)
+	for _, s := range symbols {
+		freqs[s]++
+	}
+	lengths := huffman.LengthsFromFrequencies(freqs)
+	codes, err := lengths.CanonicalCodes()
+	if err != nil {
+		return nil, fmt.Errorf("build canonical table: %w", err)
+	}
+	serialised, err := table.MarshalLengths(lengths, alphabetSize)
+	if err != nil {
+		return nil, fmt.Errorf("marshal code lengths: %w", err)
+	}
+
+	var packed bytes.Buffer
+	bw := chunks.NewBitWriter(&packed)
+	for _, s := range symbols {
+		code := codes[rune(s)]
+		if err := bw.WriteBits(code.Bits, uint(code.Length)); err != nil {
+			return nil, fmt.Errorf("pack symbols: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, fmt.Errorf("pack symbols: %w", err)
+	}
+
+	block := binary.AppendUvarint(nil, uint64(len(data)))
+	block = append(block, serialised...)
+	block = binary.AppendUvarint(block, uint64(primary))
+	block = binary.AppendUvarint(block, uint64(packed.Len()))
+	return append(block, packed.Bytes()...), nil
+}
+
+// decodeBlock reads the rest of a block of size bytes, after its length, from br
+// and returns the decoded data.
+func decodeBlock(br *bufio.Reader, size int) ([]byte, error) {
+	serialised := make([]byte, (alphabetSize+1)/2)
+	if _, err := io.ReadFull(br, serialised); err != nil {
+		return nil, fmt.Errorf("read code lengths: %w", truncated(err))
+	}
+	lengths, _, err := table.UnmarshalLengths(serialised, alphabetSize)
+	if err != nil {
+		return nil, fmt.Errorf("read code lengths: %w", err)
+	}
+	tree, err := decodingTree.BuildFromLengths(lengths)
+	if err != nil {
+		return nil, fmt.Errorf("build decoding tree: %w", err)
+	}
+	lookup, err := decodingTree.NewLookupTable(tree, decodingTree.DefaultLookupBits)
+	if err != nil {
+		return nil, fmt.Errorf("build decoding table: %w", err)
+	}
+
+	primary, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("invalid primary index: %w", truncated(err))
+	}
+	if primary > uint64(size) {
+		return nil, fmt.Errorf("primary index %d out of range for %d bytes", primary, size)
+	}
+	packedSize, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("invalid packed size: %w", truncated(err))
+	}
+	// Every byte of the block takes at most one symbol of at most
+	// table.MaxCodeLength bits.
+	if packedSize > uint64(size)*table.MaxCodeLength/chunks.ChunkSize+1 {
+		return nil, fmt.Errorf("packed size %d too large for %d bytes", packedSize, size)
+	}
+	packed := make([]byte, packedSize)
+	if _, err := io.ReadFull(br, packed); err != nil {
+		return nil, fmt.Errorf("read packed symbols: %w", truncated(err))
+	}
+
+	rest := bytes.NewReader(packed)
+	stream := chunks.NewBitReader(rest)
+	d := newFrontDecoder(size)
+	for decoded := 0; len(d.out)+d.run < size; decoded++ {
+		symbol, err := lookup.ReadSymbol(stream)
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("symbol %d: %w", decoded, err)
+		}
+		if err := d.add(int(symbol)); err != nil {
+			return nil, fmt.Errorf("symbol %d: %w", decoded, err)
+		}
+	}
+	d.flush()
+
+	if padding := stream.Buffered(); padding >= chunks.ChunkSize {
+		return nil, fmt.Errorf("unexpected %d trailing bits after last symbol", padding)
+	}
+	if rest.Len() > 0 {
+		return nil, errors.New("unexpected data after last symbol")
+	}
+
+	return inverse(d.out, int(primary))
+}
+
+// truncated reports running out of data as io.ErrUnexpectedEOF, since the
+// stream is not complete without its final zero length.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package bwt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/huffman"
+)
+
+func TestSuffixArray(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	inputs := [][]byte{
+		{},
+		[]byte("a"),
+		[]byte("banana"),
+		[]byte("mississippi"),
+		bytes.Repeat([]byte("a"), 1000),
+		bytes.Repeat([]byte("abc"), 300),
+	}
+	for range 20 {
+		data := make([]byte, rng.Intn(500))
+		for i := range data {
+			data[i] = byte('a' + rng.Intn(3))
+		}
+		inputs = append(inputs, data)
+	}
+
+	for _, data := range inputs {
+		want := make([]int32, len(data))
+		for i := range want {
+			want[i] = int32(i)
+		}
+		slices.SortFunc(want, func(a, b int32) int { return bytes.Compare(data[a:], data[b:]) })
+
+		if got := suffixArray(data); !slices.Equal(got, want) {
+			t.Errorf("suffixArray(%q) = %v, want %v", data, got, want)
+		}
+	}
+}
+
+func TestTransformInverse(t *testing.T) {
+	last, primary := transform([]byte("banana"))
+	// Rows: $banana, a$banan, ana$ban, anana$b, banana$, na$bana, nana$ba.
+	if string(last) != "annbaa" || primary != 4 {
+		t.Errorf("transform(banana) = (%q, %d), want (%q, 4)", last, primary, "annbaa")
+	}
+
+	for _, s := range []string{"", "a", "banana", "abracadabra", strings.Repeat("ab", 100), "\x00\xff\x00\xff"} {
+		last, primary := transform([]byte(s))
+		got, err := inverse(last, primary)
+		if err != nil {
+			t.Fatalf("inverse() of %q failed: %v", s, err)
+		}
+		if string(got) != s {
+			t.Errorf("inverse(transform(%q)) = %q", s, got)
+		}
+	}
+
+	if _, err := inverse([]byte("annbaa"), 0); err == nil {
+		t.Error("inverse() with primary index 0 succeeded, want error")
+	}
+}
+
+func TestMoveToFrontRuns(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "leading zeros", data: bytes.Repeat([]byte{0}, 5)},
+		{name: "runs", data: []byte("aaaabbbbbbbbbbbbcaaaaaaa")},
+		{name: "no runs", data: []byte("abcdefghij")},
+		{name: "long run", data: bytes.Repeat([]byte("z"), 100000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFrontDecoder(len(tt.data))
+			for _, s := range moveToFront(tt.data) {
+				if err := d.add(int(s)); err != nil {
+					t.Fatalf("add() failed: %v", err)
+				}
+			}
+			d.flush()
+			if !bytes.Equal(d.out, tt.data) {
+				t.Errorf("decoded %q, want %q", d.out, tt.data)
+			}
+		})
+	}
+
+	if symbols := moveToFront(bytes.Repeat([]byte{0}, 6)); !slices.Equal(symbols, []uint16{runB, runB}) {
+		t.Errorf("moveToFront() of a run of 6 = %v, want [runB runB]", symbols)
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	random := make([]byte, 100000)
+	rng.Read(random)
+	multiBlock := make([]byte, BlockSize+1000)
+	for i := range multiBlock {
+		multiBlock[i] = byte('a' + rng.Intn(8))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "single byte", data: []byte("x")},
+		{name: "run", data: bytes.Repeat([]byte("a"), 10000)},
+		{name: "text", data: []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 500))},
+		{name: "random", data: random},
+		{name: "several blocks", data: multiBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestEncodeBeatsHuffmanOnText(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	words := strings.Fields("the of and to in is that it was for on are as with his they at be this from")
+	var sb strings.Builder
+	for range 20000 {
+		sb.WriteString(words[rng.Intn(len(words))])
+		sb.WriteByte(' ')
+	}
+	data := []byte(sb.String())
+
+	var sorted, prefix bytes.Buffer
+	if err := Encode(&sorted, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if err := huffman.Encode(&prefix, bytes.NewReader(data)); err != nil {
+		t.Fatalf("huffman.Encode() failed: %v", err)
+	}
+	if sorted.Len() >= prefix.Len()*3/4 {
+		t.Errorf("bwt wrote %d bytes, want less than three quarters of Huffman's %d", sorted.Len(), prefix.Len())
+	}
+}
+
+func TestDecodeRejectsDamagedStreams(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, strings.NewReader(strings.Repeat("truncate me, please. ", 20))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	stream := encoded.Bytes()
+
+	err := Decode(io.Discard, bytes.NewReader(stream[:len(stream)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode() of a truncated stream: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Decode(io.Discard, bytes.NewReader(append(bytes.Clone(stream), 0))); err == nil {
+		t.Error("Decode() with trailing data succeeded, want error")
+	}
+}
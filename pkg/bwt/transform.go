@@ -0,0 +1,264 @@
+package bwt
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// runA and runB are the digits, worth 1 and 2, of a run of zeros written in
+	// bijective base 2, least significant digit first.
+	runA = 0
+	runB = 1
+
+	// alphabetSize is the number of symbols after zero-run-length encoding: the
+	// two run digits and move-to-front indices 1 to 255, sent as index+1.
+	alphabetSize = 257
+)
+
+// suffixArray returns the start of every suffix of data in sorted order, a
+// suffix sorting before any longer suffix it is a prefix of. It doubles the
+// length of the sorted prefixes every round and sorts by (rank of the first
+// half, rank of the second half) with two counting sorts, so it takes
+// O(n log n) time even on highly repetitive data.
+func suffixArray(data []byte) []int32 {
+	n := len(data)
+	sa := make([]int32, n)
+	rank := make([]int32, n)
+	tmp := make([]int32, n)
+
+	// Sort by the first byte.
+	var counts [256]int32
+	for _, b := range data {
+		counts[b]++
+	}
+	var sum int32
+	for b, c := range counts {
+		counts[b] = sum
+		sum += c
+	}
+	for i, b := range data {
+		sa[counts[b]] = int32(i)
+		counts[b]++
+		rank[i] = int32(b)
+	}
+	classes := 256
+
+	count := make([]int32, max(n, 256)+1)
+	for k := 1; k < n; k <<= 1 {
+		// Order by the rank at i+k: suffixes shorter than k+1 have none and come
+		// first, then the rest in the current order of their second halves.
+		p := 0
+		for i := n - k; i < n; i++ {
+			tmp[p] = int32(i)
+			p++
+		}
+		for _, s := range sa {
+			if int(s) >= k {
+				tmp[p] = s - int32(k)
+				p++
+			}
+		}
+
+		// Stable counting sort by the rank at i.
+		clear(count[:classes+1])
+		for _, s := range tmp {
+			count[rank[s]+1]++
+		}
+		for c := 1; c <= classes; c++ {
+			count[c] += count[c-1]
+		}
+		for _, s := range tmp {
+			sa[count[rank[s]]] = s
+			count[rank[s]]++
+		}
+
+		// Suffixes share a rank while both halves match.
+		second := func(i int32) int32 {
+			if int(i)+k < n {
+				return rank[int(i)+k]
+			}
+			return -1
+		}
+		tmp[sa[0]] = 0
+		for j := 1; j < n; j++ {
+			prev, cur := sa[j-1], sa[j]
+			tmp[cur] = tmp[prev]
+			if rank[prev] != rank[cur] || second(prev) != second(cur) {
+				tmp[cur]++
+			}
+		}
+		rank, tmp = tmp, rank
+		if classes = int(rank[sa[n-1]]) + 1; classes == n {
+			break
+		}
+	}
+	return sa
+}
+
+// transform returns the Burrows-Wheeler transform of data: the last byte of
+// every rotation of data followed by a unique end marker smaller than any byte,
+// in sorted order. The marker itself is left out; primary is the row it was in.
+func transform(data []byte) (last []byte, primary int) {
+	n := len(data)
+	if n == 0 {
+		return nil, 0
+	}
+
+	// Row 0 is the rotation starting at the marker; row j+1 starts at sa[j].
+	last = make([]byte, 0, n)
+	last = append(last, data[n-1])
+	for j, s := range suffixArray(data) {
+		if s == 0 {
+			primary = j + 1
+			continue
+		}
+		last = append(last, data[s-1])
+	}
+	return last, primary
+}
+
+// inverse undoes transform. Following the last-to-first mapping from row 0,
+// which ends with the final byte, yields data back to front.
+func inverse(last []byte, primary int) ([]byte, error) {
+	n := len(last)
+	if primary > n || primary == 0 && n > 0 {
+		return nil, fmt.Errorf("primary index %d out of range for %d bytes", primary, n)
+	}
+
+	// at returns the last byte of row i of the n+1 rows, skipping the marker.
+	at := func(i int) byte {
+		if i > primary {
+			return last[i-1]
+		}
+		return last[i]
+	}
+
+	// first[b] is the first row starting with b; row 0 starts with the marker.
+	var first [256]int
+	for _, b := range last {
+		first[b]++
+	}
+	next := 1
+	for b, c := range first {
+		first[b] = next
+		next += c
+	}
+
+	// lf maps every row to the row starting with its last byte, that is, the
+	// rotation one byte further back.
+	lf := make([]int32, n+1)
+	for i := range n + 1 {
+		if i == primary {
+			continue
+		}
+		b := at(i)
+		lf[i] = int32(first[b])
+		first[b]++
+	}
+
+	data := make([]byte, n)
+	row := 0
+	for k := n - 1; k >= 0; k-- {
+		if row == primary {
+			return nil, errors.New("inverse transform reached the end marker early")
+		}
+		data[k] = at(row)
+		row = int(lf[row])
+	}
+	return data, nil
+}
+
+// moveToFront replaces every byte of data with its position in a list of all
+// byte values, then moves it to the front of the list, and writes the positions
+// as symbols with runs of zeros encoded in runA and runB digits. The runs of
+// equal bytes typical of transformed text become runs of zeros.
+func moveToFront(data []byte) []uint16 {
+	var order [256]byte
+	for i := range order {
+		order[i] = byte(i)
+	}
+
+	symbols := make([]uint16, 0, len(data)/2)
+	zeros := 0
+	for _, b := range data {
+		if order[0] == b {
+			zeros++
+			continue
+		}
+		symbols = appendRun(symbols, zeros)
+		zeros = 0
+
+		i := 1
+		for order[i] != b {
+			i++
+		}
+		copy(order[1:i+1], order[:i])
+		order[0] = b
+		symbols = append(symbols, uint16(i+1))
+	}
+	return appendRun(symbols, zeros)
+}
+
+// appendRun appends a run of n zeros to symbols in bijective base 2.
+func appendRun(symbols []uint16, n int) []uint16 {
+	for n > 0 {
+		if n&1 == 1 {
+			symbols = append(symbols, runA)
+			n = (n - 1) / 2
+		} else {
+			symbols = append(symbols, runB)
+			n = (n - 2) / 2
+		}
+	}
+	return symbols
+}
+
+// frontDecoder undoes moveToFront one symbol at a time.
+type frontDecoder struct {
+	order  [256]byte
+	run    int // zeros in the pending run
+	weight int // value of the next run digit
+	out    []byte
+	size   int // number of bytes the block must decode to
+}
+
+// newFrontDecoder returns a decoder for a block of size bytes.
+func newFrontDecoder(size int) *frontDecoder {
+	d := &frontDecoder{weight: 1, out: make([]byte, 0, size), size: size}
+	for i := range d.order {
+		d.order[i] = byte(i)
+	}
+	return d
+}
+
+// add decodes symbol, returning an error if the block grows beyond its size.
+func (d *frontDecoder) add(symbol int) error {
+	if symbol == runA || symbol == runB {
+		d.run += d.weight << symbol
+		d.weight <<= 1
+		if len(d.out)+d.run > d.size {
+			return fmt.Errorf("run of %d bytes overflows block of %d bytes", d.run, d.size)
+		}
+		return nil
+	}
+	d.flush()
+	if len(d.out) == d.size {
+		return fmt.Errorf("data overflows block of %d bytes", d.size)
+	}
+
+	i := symbol - 1
+	b := d.order[i]
+	copy(d.order[1:i+1], d.order[:i])
+	d.order[0] = b
+	d.out = append(d.out, b)
+	return nil
+}
+
+// flush writes out the pending run of zeros, copies of the front byte.
+func (d *frontDecoder) flush() {
+	for ; d.run > 0; d.run-- {
+		d.out = append(d.out, d.order[0])
+	}
+	d.weight = 1
+}
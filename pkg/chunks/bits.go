@@ -0,0 +1,178 @@
+package chunks
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// bitWriterBufferSize is the number of packed bytes a BitWriter collects before
+// passing them to the underlying writer.
+const bitWriterBufferSize = 4096
+
+// BitWriter packs codes into bytes, most significant bit first. Bits are gathered
+// in a uint64 accumulator and written out in batches of whole bytes.
+type BitWriter struct {
+	w     io.Writer
+	acc   uint64 // pending bits, right-aligned
+	nbits uint   // number of pending bits in acc, always below 8 between calls
+	buf   []byte
+	err   error
+}
+
+// NewBitWriter returns a BitWriter writing packed bytes to w.
+// Flush must be called to write out the final partial byte.
+func NewBitWriter(w io.Writer) *BitWriter {
+	return &BitWriter{w: w, buf: make([]byte, 0, bitWriterBufferSize)}
+}
+
+// WriteBits appends the low n bits of bits, most significant first. n may be up to 64.
+func (bw *BitWriter) WriteBits(bits uint64, n uint) error {
+	if n > 32 {
+		if err := bw.WriteBits(bits>>32, n-32); err != nil {
+			return err
+		}
+		bits, n = bits&0xFFFFFFFF, 32
+	}
+	if bw.err != nil {
+		return bw.err
+	}
+
+	bw.acc = bw.acc<<n | bits&(1<<n-1)
+	bw.nbits += n
+	for bw.nbits >= ChunkSize {
+		bw.nbits -= ChunkSize
+		bw.buf = append(bw.buf, byte(bw.acc>>bw.nbits))
+	}
+
+	if len(bw.buf) >= bitWriterBufferSize {
+		bw.err = bw.drain()
+	}
+	return bw.err
+}
+
+// Flush pads the pending bits with zeros up to a byte boundary and writes all
+// buffered bytes to the underlying writer.
+func (bw *BitWriter) Flush() error {
+	if bw.err != nil {
+		return bw.err
+	}
+	if bw.nbits > 0 {
+		bw.buf = append(bw.buf, byte(bw.acc<<(ChunkSize-bw.nbits)))
+		bw.acc, bw.nbits = 0, 0
+	}
+	bw.err = bw.drain()
+	return bw.err
+}
+
+// drain writes the buffered bytes to the underlying writer.
+func (bw *BitWriter) drain() error {
+	if len(bw.buf) == 0 {
+		return nil
+	}
+	_, err := bw.w.Write(bw.buf)
+	bw.buf = bw.buf[:0]
+	return err
+}
+
+// BitReader reads bits from packed bytes, most significant bit first, through a
+// uint64 accumulator. ReadBits pulls bytes from the underlying reader one at a
+// time, so it never consumes more bytes than the bits requested require.
+// PeekBits tops the accumulator up to eight bytes ahead with a single Read;
+// those bits remain counted by Buffered.
+type BitReader struct {
+	r     byteReader
+	acc   uint64 // unread bits, left-aligned, with zeros after them
+	nbits uint   // number of unread bits in acc
+	buf   [8]byte
+}
+
+// byteReader is the underlying reader of a BitReader.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// NewBitReader returns a BitReader reading from r. If r is not an io.ByteReader
+// it is wrapped in a bufio.Reader.
+func NewBitReader(r io.Reader) *BitReader {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &BitReader{r: br}
+}
+
+// ReadBits reads the next n bits, n up to 32, and returns them in the low bits of
+// the result. Returns io.EOF if no bits were left, or io.ErrUnexpectedEOF if the
+// data ended part way through.
+func (br *BitReader) ReadBits(n uint) (uint64, error) {
+	for br.nbits < n {
+		b, err := br.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) && br.nbits > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		br.acc |= uint64(b) << (64 - ChunkSize - br.nbits)
+		br.nbits += ChunkSize
+	}
+
+	bits := br.acc >> (64 - n)
+	br.acc <<= n
+	br.nbits -= n
+	return bits, nil
+}
+
+// PeekBits returns the next n bits, n up to 32, without consuming them. Fewer bits
+// are available near the end of the data: the result then holds the remaining
+// bits followed by zeros, and available reports how many of them are real.
+// The end of the data is not an error.
+func (br *BitReader) PeekBits(n uint) (bits uint64, available uint, err error) {
+	if br.nbits < n {
+		err = br.fill()
+	}
+	return br.acc >> (64 - n), min(n, br.nbits), err
+}
+
+// fill tops up the accumulator with as many whole bytes as it has room for, or
+// as many as remain, so that PeekBits only reaches the underlying reader once
+// every few codes.
+func (br *BitReader) fill() error {
+	for br.nbits <= 64-ChunkSize {
+		n, err := br.r.Read(br.buf[:(64-br.nbits)/ChunkSize])
+		acc, nbits := br.acc, br.nbits
+		for _, b := range br.buf[:n] {
+			acc |= uint64(b) << (64 - ChunkSize - nbits)
+			nbits += ChunkSize
+		}
+		br.acc, br.nbits = acc, nbits
+		if errors.Is(err, io.EOF) || err == nil && n == 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SkipBits consumes n bits that a preceding PeekBits reported as available.
+func (br *BitReader) SkipBits(n uint) {
+	n = min(n, br.nbits)
+	br.acc <<= n
+	br.nbits -= n
+}
+
+// ReadBit reads a single bit.
+func (br *BitReader) ReadBit() (uint, error) {
+	bit, err := br.ReadBits(1)
+	return uint(bit), err
+}
+
+// Buffered returns the number of bits already read from the underlying reader
+// but not yet returned, such as the padding at the end of the last byte.
+func (br *BitReader) Buffered() uint {
+	return br.nbits
+}
@@ -0,0 +1,74 @@
+package chunks
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestBitWriterPacksMostSignificantFirst(t *testing.T) {
+	bits := "0010001111110101100000011"
+
+	var buf bytes.Buffer
+	bw := NewBitWriter(&buf)
+	for _, c := range bits {
+		if err := bw.WriteBits(uint64(c-'0'), 1); err != nil {
+			t.Fatalf("WriteBits() failed: %v", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	// The bits in order, with the last byte padded by zeros.
+	want := []byte{0b00100011, 0b11110101, 0b10000001, 0b10000000}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("BitWriter wrote %08b, want %08b", buf.Bytes(), want)
+	}
+}
+
+func TestBitReaderRoundTrip(t *testing.T) {
+	fields := []struct {
+		bits uint64
+		n    uint
+	}{
+		{0b1, 1}, {0b0110, 4}, {0xABC, 12}, {0, 3}, {0xDEADBEEF, 32},
+		{0x0123456789ABCDEF, 64}, {0x7F, 7}, {0x1, 15},
+	}
+
+	var buf bytes.Buffer
+	bw := NewBitWriter(&buf)
+	for _, f := range fields {
+		if err := bw.WriteBits(f.bits, f.n); err != nil {
+			t.Fatalf("WriteBits() failed: %v", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	br := NewBitReader(&buf)
+	for i, f := range fields {
+		var got uint64
+		for n := f.n; n > 0; {
+			step := min(n, 32)
+			part, err := br.ReadBits(step)
+			if err != nil {
+				t.Fatalf("field %d: ReadBits() failed: %v", i, err)
+			}
+			got = got<<step | part
+			n -= step
+		}
+		if got != f.bits {
+			t.Errorf("field %d: ReadBits() = %#x, want %#x", i, got, f.bits)
+		}
+	}
+
+	if padding := br.Buffered(); padding >= ChunkSize {
+		t.Errorf("Buffered() = %d after last field, want fewer than %d", padding, ChunkSize)
+	}
+	if _, err := br.ReadBits(ChunkSize); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadBits() past the end: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,77 @@
+package chunks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// hexDigits are the characters used for HexChunk output.
+const hexDigits = "0123456789ABCDEF"
+
+// hexWriter converts written bytes into space-separated HexChunks.
+type hexWriter struct {
+	w       io.Writer
+	started bool
+}
+
+// NewHexWriter returns a writer that writes every byte to w as a HexChunk,
+// separated by spaces, producing the same text as HexChunks.ToString.
+func NewHexWriter(w io.Writer) io.Writer {
+	return &hexWriter{w: w}
+}
+
+// Write encodes p as hex chunks. It returns len(p) on success.
+func (hw *hexWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(p)*(hexChunkSize+len(hexChunkSep)))
+	for _, b := range p {
+		if hw.started {
+			buf = append(buf, hexChunkSep...)
+		}
+		buf = append(buf, hexDigits[b>>4], hexDigits[b&0x0F])
+		hw.started = true
+	}
+
+	if _, err := hw.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// hexReader converts whitespace-separated HexChunks back into bytes.
+type hexReader struct {
+	scanner *bufio.Scanner
+}
+
+// NewHexReader returns a reader that parses the whitespace-separated HexChunks
+// read from r and yields the bytes they represent. Invalid chunks are reported
+// as errors by Read.
+func NewHexReader(r io.Reader) io.Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	return &hexReader{scanner: scanner}
+}
+
+// Read fills p with decoded bytes, one per hex chunk.
+func (hr *hexReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if !hr.scanner.Scan() {
+			if err := hr.scanner.Err(); err != nil {
+				return n, err
+			}
+			if n == 0 {
+				return 0, io.EOF
+			}
+			break
+		}
+
+		value, err := HexChunk(hr.scanner.Text()).ToByte()
+		if err != nil {
+			return n, fmt.Errorf("parse hex chunks: %w", err)
+		}
+		p[n] = value
+		n++
+	}
+	return n, nil
+}
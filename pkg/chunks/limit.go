@@ -0,0 +1,53 @@
+package chunks
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrLimit is returned by a LimitedWriter for data past its limit.
+var ErrLimit = errors.New("output exceeds limit")
+
+// LimitedWriter writes to W but rejects anything beyond the first N bytes, the
+// way io.LimitedReader stops reading. Decoders given one can tell a stream that
+// expands past the size it should decode to from a valid one as soon as the
+// limit is passed, instead of first holding all of its output.
+type LimitedWriter struct {
+	W io.Writer // underlying writer
+	N int64     // bytes remaining
+}
+
+// LimitWriter returns a writer that writes to w until n bytes have been written
+// and returns ErrLimit for any more.
+func LimitWriter(w io.Writer, n int64) *LimitedWriter {
+	return &LimitedWriter{W: w, N: n}
+}
+
+// Write writes as much of p as fits within the limit. If p does not fit, the
+// part that does is written and ErrLimit is returned.
+func (lw *LimitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) <= lw.N {
+		n, err := lw.W.Write(p)
+		lw.N -= int64(n)
+		return n, err
+	}
+
+	n, err := lw.W.Write(p[:lw.N])
+	lw.N -= int64(n)
+	if err == nil {
+		err = ErrLimit
+	}
+	return n, err
+}
+
+// Remaining returns the number of bytes that can still be written to w: N for a
+// LimitedWriter and math.MaxInt64 for any other writer. Decoders that can tell
+// the size of their output before producing it use it to reject a stream at the
+// first symbol past the limit.
+func Remaining(w io.Writer) int64 {
+	if lw, ok := w.(*LimitedWriter); ok {
+		return lw.N
+	}
+	return math.MaxInt64
+}
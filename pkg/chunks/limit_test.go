@@ -0,0 +1,52 @@
+package chunks
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestLimitWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int64
+		writes  []string
+		want    string
+		wantErr error
+	}{
+		{name: "within limit", limit: 8, writes: []string{"abc", "defg"}, want: "abcdefg"},
+		{name: "exactly at limit", limit: 7, writes: []string{"abc", "defg"}, want: "abcdefg"},
+		{name: "one byte past limit", limit: 6, writes: []string{"abc", "defg"}, want: "abcdef", wantErr: ErrLimit},
+		{name: "write after limit", limit: 3, writes: []string{"abc", "d"}, want: "abc", wantErr: ErrLimit},
+		{name: "zero limit", limit: 0, writes: []string{"a"}, want: "", wantErr: ErrLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			lw := LimitWriter(&buf, tt.limit)
+			var err error
+			for _, s := range tt.writes {
+				if _, err = lw.Write([]byte(s)); err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Write() error = %v, want %v", err, tt.wantErr)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("wrote %q, want %q", buf.String(), tt.want)
+			}
+			if got, want := Remaining(lw), tt.limit-int64(len(tt.want)); got != want {
+				t.Errorf("Remaining() = %d, want %d", got, want)
+			}
+		})
+	}
+
+	if got := Remaining(io.Discard); got != math.MaxInt64 {
+		t.Errorf("Remaining(io.Discard) = %d, want %d", got, int64(math.MaxInt64))
+	}
+}
@@ -0,0 +1,65 @@
+package codec
+
+import (
+	"github.com/flexer2006/simpleArchiver-golang/pkg/adaptiveHuffman"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/bwt"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/huffman"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/lz77"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/lzw"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/rangeCoder"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/rans"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlc"
+)
+
+// Identifiers of the built-in codecs. They are part of the file format and must
+// never be reused for a different algorithm.
+const (
+	// IDVLC identifies the fixed-table variable-length code from the vlc package.
+	IDVLC uint8 = 0
+	// IDHuffman identifies the static Huffman code from the huffman package.
+	IDHuffman uint8 = 1
+	// IDAdaptiveHuffman identifies the single-pass FGK code from the adaptiveHuffman package.
+	IDAdaptiveHuffman uint8 = 2
+	// IDRange identifies the adaptive order-0 range coder from the rangeCoder package.
+	IDRange uint8 = 3
+	// IDRANS identifies the static rANS code from the rans package.
+	IDRANS uint8 = 4
+	// IDLZ77 identifies the Huffman-coded LZ77 dictionary codec from the lz77 package.
+	IDLZ77 uint8 = 5
+	// IDLZW identifies the variable-width LZW dictionary codec from the lzw package.
+	IDLZW uint8 = 6
+	// IDBWT identifies the bzip2-style block-sorting codec from the bwt package.
+	IDBWT uint8 = 7
+)
+
+// Default is the name of the codec used when none is selected.
+const Default = "vlc"
+
+// init registers the built-in codecs.
+func init() {
+	Register(New(IDVLC, "vlc", vlc.Encode, vlc.Decode))
+	Register(New(IDHuffman, "huffman", huffman.Encode, huffman.Decode))
+	Register(New(IDAdaptiveHuffman, "adaptive-huffman", adaptiveHuffman.Encode, adaptiveHuffman.Decode))
+	Register(New(IDRange, "range", rangeCoder.Encode, rangeCoder.Decode))
+	Register(New(IDRANS, "rans", rans.Encode, rans.Decode))
+	Register(New(IDLZ77, "lz77", lz77.Encode, lz77.Decode))
+	Register(New(IDLZW, "lzw", lzw.Encode, lzw.Decode))
+	Register(New(IDBWT, "bwt", bwt.Encode, bwt.Decode))
+}
+
+// NewLZ77 returns the lz77 codec with a sliding window of 1<<windowBits bytes
+// instead of lz77.DefaultWindowBits. The window size is recorded in the stream,
+// so the registered lz77 codec decodes its output.
+//
+// Parameters:
+//   - windowBits: The window size in bits, from lz77.MinWindowBits to lz77.MaxWindowBits.
+//
+// Returns:
+//   - Codec: The configured codec.
+//   - error: An error if windowBits is out of range.
+func NewLZ77(windowBits int) (Codec, error) {
+	if err := lz77.ValidateWindowBits(windowBits); err != nil {
+		return nil, err
+	}
+	return New(IDLZ77, "lz77", lz77.Encoder{WindowBits: windowBits}.Encode, lz77.Decode), nil
+}
@@ -0,0 +1,109 @@
+// Package codec defines the Codec interface implemented by every compression
+// algorithm and a registry that maps codec names (used on the command line) and
+// identifiers (stored in the container header) to implementations. The built-in
+// codecs are registered when the package is initialised.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownCodec is returned when a name or identifier has no registered codec.
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec compresses and decompresses data streams.
+type Codec interface {
+	// Name is the identifier used to select the codec, e.g. with the --codec flag.
+	Name() string
+	// ID is the identifier stored in the container header.
+	ID() uint8
+	// Encode reads src to the end and writes a self-contained encoded stream to dst.
+	Encode(dst io.Writer, src io.Reader) error
+	// Decode reads an encoded stream from src to the end and writes the original data to dst.
+	Decode(dst io.Writer, src io.Reader) error
+}
+
+// registry holds the registered codecs, indexed by name and by identifier.
+var registry = struct {
+	sync.RWMutex
+	byName map[string]Codec
+	byID   map[uint8]Codec
+}{
+	byName: make(map[string]Codec),
+	byID:   make(map[uint8]Codec),
+}
+
+// Register makes a codec available by its name and identifier.
+// It panics if either is already taken, since that is a programming error.
+func Register(c Codec) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.byName[c.Name()]; ok {
+		panic(fmt.Sprintf("codec: Register called twice for name %q", c.Name()))
+	}
+	if _, ok := registry.byID[c.ID()]; ok {
+		panic(fmt.Sprintf("codec: Register called twice for id %d", c.ID()))
+	}
+	registry.byName[c.Name()] = c
+	registry.byID[c.ID()] = c
+}
+
+// Lookup returns the codec registered under name.
+func Lookup(name string) (Codec, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	c, ok := registry.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCodec, name)
+	}
+	return c, nil
+}
+
+// ByID returns the codec registered under the identifier id.
+func ByID(id uint8) (Codec, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	c, ok := registry.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: id %d", ErrUnknownCodec, id)
+	}
+	return c, nil
+}
+
+// Names returns the names of all registered codecs in sorted order.
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.byName))
+	for name := range registry.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns a Codec built from a pair of stream functions, as implemented by
+// the algorithm packages.
+func New(id uint8, name string, encode, decode func(dst io.Writer, src io.Reader) error) Codec {
+	return funcCodec{id: id, name: name, encode: encode, decode: decode}
+}
+
+// funcCodec adapts a pair of stream functions to the Codec interface.
+type funcCodec struct {
+	id             uint8
+	name           string
+	encode, decode func(dst io.Writer, src io.Reader) error
+}
+
+func (c funcCodec) Name() string                              { return c.name }
+func (c funcCodec) ID() uint8                                 { return c.id }
+func (c funcCodec) Encode(dst io.Writer, src io.Reader) error { return c.encode(dst, src) }
+func (c funcCodec) Decode(dst io.Writer, src io.Reader) error { return c.decode(dst, src) }
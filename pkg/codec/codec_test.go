@@ -0,0 +1,184 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+func TestBuiltinCodecsRoundTrip(t *testing.T) {
+	inputs := [][]byte{
+		{},
+		[]byte("a"),
+		[]byte("The quick brown fox jumps over the lazy dog.\nTHE END\n"),
+		{0x00, 0xff, 0x80, 0x7f, 0x00, 0x00},
+	}
+
+	for _, name := range Names() {
+		c, err := Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) failed: %v", name, err)
+		}
+
+		for _, input := range inputs {
+			var encoded, decoded bytes.Buffer
+			if err := c.Encode(&encoded, bytes.NewReader(input)); err != nil {
+				t.Fatalf("%s: Encode(%q) failed: %v", name, input, err)
+			}
+			if err := c.Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("%s: Decode() of %q failed: %v", name, input, err)
+			}
+			if !bytes.Equal(decoded.Bytes(), input) {
+				t.Errorf("%s: Decode(Encode(%q)) = %q", name, input, decoded.Bytes())
+			}
+		}
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+	return n, err
+}
+
+func TestBuiltinCodecsStopAtLimit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"the ", "Quick ", "BROWN ", "fox ", "jumps\n", "over ", "lazy ", "dogs, "}
+	var input []byte
+	for len(input) < 1<<20 {
+		input = append(input, words[rng.Intn(len(words))]...)
+	}
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			c, err := Lookup(name)
+			if err != nil {
+				t.Fatalf("Lookup(%q) failed: %v", name, err)
+			}
+			var encoded bytes.Buffer
+			if err := c.Encode(&encoded, bytes.NewReader(input)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			var decoded bytes.Buffer
+			limit := int64(len(input)) - 1
+			err = c.Decode(chunks.LimitWriter(&decoded, limit), bytes.NewReader(encoded.Bytes()))
+			if !errors.Is(err, chunks.ErrLimit) {
+				t.Errorf("Decode() with limit %d: error = %v, want %v", limit, err, chunks.ErrLimit)
+			}
+			if int64(decoded.Len()) > limit {
+				t.Errorf("Decode() wrote %d bytes, want at most %d", decoded.Len(), limit)
+			}
+
+			// A small limit is noticed long before the end of the stream.
+			src := &countingReader{r: bytes.NewReader(encoded.Bytes())}
+			err = c.Decode(chunks.LimitWriter(io.Discard, 1), src)
+			if !errors.Is(err, chunks.ErrLimit) {
+				t.Errorf("Decode() with limit 1: error = %v, want %v", err, chunks.ErrLimit)
+			}
+			if src.n > encoded.Len()/2 {
+				t.Errorf("Decode() with limit 1 read %d of %d encoded bytes, want at most half", src.n, encoded.Len())
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c, err := Lookup(Default)
+	if err != nil {
+		t.Fatalf("Lookup(%q) failed: %v", Default, err)
+	}
+
+	byID, err := ByID(c.ID())
+	if err != nil {
+		t.Fatalf("ByID(%d) failed: %v", c.ID(), err)
+	}
+	if byID.Name() != c.Name() {
+		t.Errorf("ByID(%d) = %q, want %q", c.ID(), byID.Name(), c.Name())
+	}
+
+	if _, err := Lookup("no-such-codec"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Lookup() error = %v, want %v", err, ErrUnknownCodec)
+	}
+	if _, err := ByID(255); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("ByID() error = %v, want %v", err, ErrUnknownCodec)
+	}
+}
+
+func TestNewLZ77(t *testing.T) {
+	c, err := NewLZ77(10)
+	if err != nil {
+		t.Fatalf("NewLZ77() failed: %v", err)
+	}
+	registered, err := ByID(c.ID())
+	if err != nil {
+		t.Fatalf("ByID(%d) failed: %v", c.ID(), err)
+	}
+
+	input := bytes.Repeat([]byte("window size is stored in the stream. "), 100)
+	var encoded, decoded bytes.Buffer
+	if err := c.Encode(&encoded, bytes.NewReader(input)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if err := registered.Decode(&decoded, &encoded); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if !bytes.Equal(decoded.Bytes(), input) {
+		t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(input))
+	}
+
+	if _, err := NewLZ77(64); err == nil {
+		t.Error("NewLZ77(64) succeeded, want error")
+	}
+}
+
+func TestRegisterPanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register() expected panic for duplicate name")
+		}
+	}()
+	Register(New(254, Default, nil, nil))
+}
+
+// BenchmarkDecode compares the decoding speed and compression ratio of the
+// codecs sharing the byte frequency model on the same skewed input.
+func BenchmarkDecode(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	input := make([]byte, 1<<20)
+	for i := range input {
+		input[i] = byte('a' + rng.ExpFloat64()*3)
+	}
+
+	for _, name := range []string{"huffman", "rans"} {
+		b.Run(name, func(b *testing.B) {
+			c, err := Lookup(name)
+			if err != nil {
+				b.Fatalf("Lookup(%q) failed: %v", name, err)
+			}
+			var encoded bytes.Buffer
+			if err := c.Encode(&encoded, bytes.NewReader(input)); err != nil {
+				b.Fatalf("Encode() failed: %v", err)
+			}
+
+			b.SetBytes(int64(len(input)))
+			b.ResetTimer()
+			for range b.N {
+				if err := c.Decode(io.Discard, bytes.NewReader(encoded.Bytes())); err != nil {
+					b.Fatalf("Decode() failed: %v", err)
+				}
+			}
+			b.ReportMetric(float64(encoded.Len())/float64(len(input)), "ratio")
+		})
+	}
+}
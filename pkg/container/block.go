@@ -0,0 +1,148 @@
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// The payload after the header is a sequence of blocks, each holding up to
+// BlockSize bytes of original data encoded independently by the codec:
+//
+//	block   = uvarint(raw length) uvarint(packed length) uint32 crc32c(raw) packed bytes
+//	end     = uvarint(0)
+//	trailer = uint64 total original length, uint32 crc32c(original) [sha256(original)]
+//
+// Fixed-size integers are little-endian, and the SHA-256 digest is present only
+// with FlagSHA256. Blocks bound the memory needed by packers and unpackers
+// regardless of the size of the input, and their checksums locate damage.
+const (
+	// BlockSize is the largest amount of original data held in a single block.
+	BlockSize = 256 << 10
+
+	// MaxPackedBlockSize is the largest packed block a reader accepts. It leaves
+	// room for codecs that expand incompressible data while still rejecting
+	// corrupt lengths before allocating memory for them.
+	MaxPackedBlockSize = 4 * BlockSize
+
+	// TrailerSize is the encoded size of Trailer in bytes without a SHA-256
+	// digest: length (8) + CRC32C (4). See Header.TrailerSize.
+	TrailerSize = 12
+)
+
+// castagnoli is the CRC32C table used for every checksum in the format.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum returns the CRC32C checksum of data.
+func Checksum(data []byte) uint32 {
+	return crc32.Checksum(data, castagnoli)
+}
+
+// NewChecksum returns a hash computing the CRC32C checksum used by Checksum.
+func NewChecksum() hash.Hash32 {
+	return crc32.New(castagnoli)
+}
+
+// BlockHeader precedes the packed bytes of a block. A zero RawLength marks the
+// end of the payload and has no other fields.
+type BlockHeader struct {
+	RawLength    uint64 // Number of original bytes in the block
+	PackedLength uint64 // Number of packed bytes that follow the header
+	CRC32C       uint32 // Checksum of the original bytes
+}
+
+// MarshalBinary encodes the block header as two uvarints and the checksum, or
+// as a single zero for the end marker.
+func (b BlockHeader) MarshalBinary() ([]byte, error) {
+	if b.RawLength > BlockSize {
+		return nil, fmt.Errorf("block of %d bytes exceeds %d", b.RawLength, BlockSize)
+	}
+	if b.PackedLength > MaxPackedBlockSize {
+		return nil, fmt.Errorf("packed block of %d bytes exceeds %d", b.PackedLength, MaxPackedBlockSize)
+	}
+	buf := binary.AppendUvarint(nil, b.RawLength)
+	if b.RawLength == 0 {
+		return buf, nil
+	}
+	buf = binary.AppendUvarint(buf, b.PackedLength)
+	return binary.LittleEndian.AppendUint32(buf, b.CRC32C), nil
+}
+
+// ReadBlockHeader reads and validates a block header from r.
+// Returns an error for lengths beyond BlockSize or MaxPackedBlockSize.
+func ReadBlockHeader(r io.ByteReader) (BlockHeader, error) {
+	raw, err := readUvarint(r)
+	if err != nil {
+		return BlockHeader{}, fmt.Errorf("read block length: %w", err)
+	}
+	if raw == 0 {
+		return BlockHeader{}, nil
+	}
+	if raw > BlockSize {
+		return BlockHeader{}, fmt.Errorf("block of %d bytes exceeds %d", raw, BlockSize)
+	}
+
+	packed, err := readUvarint(r)
+	if err != nil {
+		return BlockHeader{}, fmt.Errorf("read packed block length: %w", err)
+	}
+	if packed > MaxPackedBlockSize {
+		return BlockHeader{}, fmt.Errorf("packed block of %d bytes exceeds %d", packed, MaxPackedBlockSize)
+	}
+
+	var crc [4]byte
+	for i := range crc {
+		if crc[i], err = r.ReadByte(); err != nil {
+			return BlockHeader{}, fmt.Errorf("read block checksum: %w", ErrTruncated)
+		}
+	}
+
+	return BlockHeader{RawLength: raw, PackedLength: packed, CRC32C: binary.LittleEndian.Uint32(crc[:])}, nil
+}
+
+// Trailer follows the end-of-payload marker.
+type Trailer struct {
+	Length uint64 // Total original data length in bytes
+	CRC32C uint32 // Checksum of the original data
+	SHA256 []byte // SHA-256 digest of the original data, only with FlagSHA256
+}
+
+// MarshalBinary encodes the trailer into its on-disk form of Header.TrailerSize bytes.
+func (t Trailer) MarshalBinary() ([]byte, error) {
+	if len(t.SHA256) != 0 && len(t.SHA256) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA-256 digest length %d", len(t.SHA256))
+	}
+	buf := binary.LittleEndian.AppendUint64(nil, t.Length)
+	buf = binary.LittleEndian.AppendUint32(buf, t.CRC32C)
+	return append(buf, t.SHA256...), nil
+}
+
+// ReadTrailer reads a trailer of the stream described by h from r.
+func ReadTrailer(r io.Reader, h Header) (Trailer, error) {
+	buf := make([]byte, h.TrailerSize())
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return Trailer{}, fmt.Errorf("%w: missing trailer", ErrTruncated)
+		}
+		return Trailer{}, fmt.Errorf("read trailer: %w", err)
+	}
+
+	t := Trailer{Length: binary.LittleEndian.Uint64(buf), CRC32C: binary.LittleEndian.Uint32(buf[8:])}
+	if len(buf) > TrailerSize {
+		t.SHA256 = buf[TrailerSize:]
+	}
+	return t, nil
+}
+
+// readUvarint reads a uvarint, reporting a clean or partial EOF as ErrTruncated.
+func readUvarint(r io.ByteReader) (uint64, error) {
+	v, err := binary.ReadUvarint(r)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, ErrTruncated
+	}
+	return v, err
+}
@@ -0,0 +1,348 @@
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"time"
+)
+
+// An archive (a header with FlagArchive set) holds several entries. The payload of
+// each entry, laid out exactly like the payload of a single-file stream, follows
+// the header in turn, and the central directory describing them comes last:
+//
+//	archive   = header payload... directory footer
+//	directory = uvarint(entry count) entry...
+//	entry     = uvarint(record length) record
+//	footer    = "SVLD" uint64 little-endian directory offset
+//
+// Entry records are length-prefixed so that fields appended by later versions can
+// be skipped by readers that do not know them, and fields after the payload
+// location may be missing, in which case they keep their zero values.
+const (
+	// FooterSize is the encoded size of Footer in bytes: magic (4) + offset (8).
+	FooterSize = len(footerMagic) + 8
+
+	// maxNameLength bounds entry names so corrupt lengths are rejected before
+	// allocating memory for them.
+	maxNameLength = 4096
+
+	// maxXattrs and maxXattrSize bound the extended attributes of an entry.
+	maxXattrs    = 1024
+	maxXattrSize = 64 << 10
+)
+
+// footerMagic identifies the footer at the end of an archive.
+var footerMagic = [4]byte{'S', 'V', 'L', 'D'}
+
+// ErrBadFooter is returned when an archive does not end with a valid footer.
+var ErrBadFooter = errors.New("not a vlc archive: bad footer")
+
+// Entry describes one file stored in an archive.
+type Entry struct {
+	Name       string      // Slash-separated path of the file inside the archive
+	Size       uint64      // Original size in bytes
+	Mode       fs.FileMode // Type and permission bits
+	ModTime    time.Time   // Modification time
+	Codec      uint8       // Codec identifier of the payload, see codec.Codec.ID
+	Offset     uint64      // Offset of the payload from the start of the archive
+	PackedSize uint64      // Size of the payload in bytes
+
+	AccessTime time.Time         // Access time, zero if not recorded
+	UID, GID   uint32            // Numeric owner and group
+	User       string            // Owner name, empty if not recorded
+	Group      string            // Group name, empty if not recorded
+	Xattrs     map[string][]byte // Extended attributes, nil if not recorded
+
+	CRC32C uint32 // Checksum of the original data, as in the payload's Trailer
+	SHA256 []byte // SHA-256 digest of the original data, nil if not recorded
+}
+
+// Directory lists the entries of an archive in the order their payloads are stored.
+type Directory []Entry
+
+// MarshalBinary encodes the directory into its on-disk form.
+func (d Directory) MarshalBinary() ([]byte, error) {
+	buf := binary.AppendUvarint(nil, uint64(len(d)))
+	for _, e := range d {
+		if e.Name == "" || len(e.Name) > maxNameLength {
+			return nil, fmt.Errorf("invalid entry name length %d", len(e.Name))
+		}
+
+		record := binary.AppendUvarint(nil, uint64(len(e.Name)))
+		record = append(record, e.Name...)
+		record = binary.AppendUvarint(record, e.Size)
+		record = binary.AppendUvarint(record, uint64(e.Mode))
+		record = appendTime(record, e.ModTime)
+		record = append(record, e.Codec)
+		record = binary.AppendUvarint(record, e.Offset)
+		record = binary.AppendUvarint(record, e.PackedSize)
+
+		record = appendTime(record, e.AccessTime)
+		record = binary.AppendUvarint(record, uint64(e.UID))
+		record = binary.AppendUvarint(record, uint64(e.GID))
+		record = appendString(record, e.User)
+		record = appendString(record, e.Group)
+		if len(e.Xattrs) > maxXattrs {
+			return nil, fmt.Errorf("entry %q: %d extended attributes exceed %d", e.Name, len(e.Xattrs), maxXattrs)
+		}
+		record = binary.AppendUvarint(record, uint64(len(e.Xattrs)))
+		for _, name := range slices.Sorted(maps.Keys(e.Xattrs)) {
+			if len(name) > maxXattrSize || len(e.Xattrs[name]) > maxXattrSize {
+				return nil, fmt.Errorf("entry %q: extended attribute %q too large", e.Name, name)
+			}
+			record = appendString(record, name)
+			record = appendString(record, string(e.Xattrs[name]))
+		}
+
+		record = binary.LittleEndian.AppendUint32(record, e.CRC32C)
+		record = appendString(record, string(e.SHA256))
+
+		buf = binary.AppendUvarint(buf, uint64(len(record)))
+		buf = append(buf, record...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a directory from data, which must hold exactly the
+// encoded directory.
+func (d *Directory) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	count, err := readUvarint(r)
+	if err != nil {
+		return fmt.Errorf("read entry count: %w", err)
+	}
+	// Every entry takes at least two bytes, which bounds the allocation below.
+	if count > uint64(len(data)) {
+		return fmt.Errorf("%w: %d entries in %d bytes", ErrTruncated, count, len(data))
+	}
+
+	parsed := make(Directory, 0, count)
+	for i := range count {
+		length, err := readUvarint(r)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		if length > uint64(r.Len()) {
+			return fmt.Errorf("entry %d: %w", i, ErrTruncated)
+		}
+		record := make([]byte, length)
+		_, _ = r.Read(record)
+
+		e, err := unmarshalEntry(record)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		parsed = append(parsed, e)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("unexpected %d bytes after directory", r.Len())
+	}
+
+	*d = parsed
+	return nil
+}
+
+// unmarshalEntry decodes the known fields of an entry record and ignores the rest.
+func unmarshalEntry(record []byte) (Entry, error) {
+	rr := recordReader{r: bytes.NewReader(record)}
+
+	nameLength := rr.uvarint()
+	if rr.err == nil && (nameLength == 0 || nameLength > maxNameLength) {
+		return Entry{}, fmt.Errorf("invalid name length %d", nameLength)
+	}
+	e := Entry{Name: string(rr.bytes(nameLength)), Size: rr.uvarint()}
+	mode := rr.uvarint()
+	e.ModTime = rr.time()
+	e.Codec = rr.byte()
+	e.Offset = rr.uvarint()
+	e.PackedSize = rr.uvarint()
+	if mode > uint64(^uint32(0)) {
+		return Entry{}, fmt.Errorf("invalid mode %o", mode)
+	}
+	e.Mode = fs.FileMode(mode)
+
+	if rr.err == nil && rr.r.Len() > 0 {
+		e.AccessTime = rr.time()
+		e.UID = rr.uint32()
+		e.GID = rr.uint32()
+		e.User = string(rr.bytes(rr.uvarint()))
+		e.Group = string(rr.bytes(rr.uvarint()))
+
+		count := rr.uvarint()
+		if count > maxXattrs {
+			return Entry{}, fmt.Errorf("%d extended attributes exceed %d", count, maxXattrs)
+		}
+		for range count {
+			name := string(rr.bytes(rr.uvarint()))
+			value := rr.bytes(rr.uvarint())
+			if rr.err != nil {
+				break
+			}
+			if e.Xattrs == nil {
+				e.Xattrs = make(map[string][]byte, count)
+			}
+			e.Xattrs[name] = value
+		}
+
+		e.CRC32C = rr.fixed32()
+		if digest := rr.bytes(rr.uvarint()); len(digest) > 0 {
+			e.SHA256 = digest
+		}
+	}
+
+	if rr.err != nil {
+		return Entry{}, rr.err
+	}
+	return e, nil
+}
+
+// appendTime appends t as varint seconds and uvarint nanoseconds since the Unix
+// epoch. The zero time is written as zero seconds and nanoseconds.
+func appendTime(buf []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return append(buf, 0, 0)
+	}
+	buf = binary.AppendVarint(buf, t.Unix())
+	return binary.AppendUvarint(buf, uint64(t.Nanosecond()))
+}
+
+// appendString appends s preceded by its length as a uvarint.
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// recordReader reads the fields of an entry record, remembering the first error
+// so that a run of fields can be read before checking it.
+type recordReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (rr *recordReader) uvarint() uint64 {
+	if rr.err != nil {
+		return 0
+	}
+	var v uint64
+	v, rr.err = readUvarint(rr.r)
+	return v
+}
+
+func (rr *recordReader) varint() int64 {
+	if rr.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(rr.r)
+	if err != nil {
+		rr.err = ErrTruncated
+	}
+	return v
+}
+
+func (rr *recordReader) uint32() uint32 {
+	v := rr.uvarint()
+	if v > uint64(^uint32(0)) && rr.err == nil {
+		rr.err = fmt.Errorf("value %d out of range", v)
+	}
+	return uint32(v)
+}
+
+func (rr *recordReader) fixed32() uint32 {
+	buf := rr.bytes(4)
+	if rr.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(buf)
+}
+
+// time reads a time written by appendTime, mapping zero seconds and nanoseconds
+// back to the zero time.
+func (rr *recordReader) time() time.Time {
+	sec, nsec := rr.varint(), rr.uvarint()
+	if rr.err != nil || sec == 0 && nsec == 0 {
+		return time.Time{}
+	}
+	if nsec >= uint64(time.Second) {
+		rr.err = fmt.Errorf("invalid time %d.%09d", sec, nsec)
+		return time.Time{}
+	}
+	return time.Unix(sec, int64(nsec))
+}
+
+func (rr *recordReader) byte() uint8 {
+	if rr.err != nil {
+		return 0
+	}
+	b, err := rr.r.ReadByte()
+	if err != nil {
+		rr.err = ErrTruncated
+	}
+	return b
+}
+
+func (rr *recordReader) bytes(n uint64) []byte {
+	if rr.err != nil {
+		return nil
+	}
+	if n > uint64(rr.r.Len()) || n > maxXattrSize {
+		rr.err = ErrTruncated
+		return nil
+	}
+	buf := make([]byte, n)
+	_, _ = rr.r.Read(buf)
+	return buf
+}
+
+// Footer ends an archive and locates its central directory.
+type Footer struct {
+	DirectoryOffset uint64 // Offset of the directory from the start of the archive
+}
+
+// MarshalBinary encodes the footer into its FooterSize-byte on-disk form.
+func (f Footer) MarshalBinary() ([]byte, error) {
+	return binary.LittleEndian.AppendUint64(footerMagic[:], f.DirectoryOffset), nil
+}
+
+// ReadDirectory reads the footer at the end of an archive of the given size and
+// the central directory it points to.
+func ReadDirectory(r io.ReaderAt, size int64) (Directory, error) {
+	if size < int64(HeaderSize+FooterSize) {
+		return nil, fmt.Errorf("%w: archive of %d bytes", ErrTruncated, size)
+	}
+
+	footer := make([]byte, FooterSize)
+	if _, err := r.ReadAt(footer, size-int64(FooterSize)); err != nil {
+		return nil, fmt.Errorf("read footer: %w", err)
+	}
+	if !bytes.Equal(footer[:len(footerMagic)], footerMagic[:]) {
+		return nil, ErrBadFooter
+	}
+
+	offset := binary.LittleEndian.Uint64(footer[len(footerMagic):])
+	end := uint64(size - int64(FooterSize))
+	if offset < uint64(HeaderSize) || offset > end {
+		return nil, fmt.Errorf("%w: directory offset %d", ErrBadFooter, offset)
+	}
+
+	data := make([]byte, end-offset)
+	if _, err := r.ReadAt(data, int64(offset)); err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	var d Directory
+	if err := d.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+	for _, e := range d {
+		if e.Offset < uint64(HeaderSize) || e.PackedSize > offset || e.Offset > offset-e.PackedSize {
+			return nil, fmt.Errorf("entry %q: payload outside archive", e.Name)
+		}
+	}
+	return d, nil
+}
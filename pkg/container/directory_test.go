@@ -0,0 +1,56 @@
+package container
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDirectoryRoundTrip(t *testing.T) {
+	dir := Directory{
+		{Name: "a.txt", Size: 10, Mode: 0644, ModTime: time.Unix(1700000000, 123), Codec: 1, Offset: 7, PackedSize: 20},
+		{Name: "docs/b.txt", Mode: 0600, ModTime: time.Unix(-5, 0), Offset: 27, PackedSize: 9,
+			AccessTime: time.Unix(1700000100, 0), UID: 1000, GID: 100, User: "alice", Group: "users",
+			Xattrs: map[string][]byte{"user.origin": []byte("https://example.com"), "user.empty": {}},
+			CRC32C: 0xDEADBEEF, SHA256: make([]byte, 32)},
+	}
+
+	data, err := dir.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+	var got Directory
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, dir) {
+		t.Errorf("UnmarshalBinary() = %+v, want %+v", got, dir)
+	}
+
+	for cut := 1; cut < len(data); cut++ {
+		if err := got.UnmarshalBinary(data[:len(data)-cut]); err == nil {
+			t.Errorf("UnmarshalBinary() with %d bytes cut succeeded, want error", cut)
+		}
+	}
+}
+
+func TestDirectorySkipsUnknownFields(t *testing.T) {
+	entry, err := Directory{{Name: "a", Size: 1, ModTime: time.Unix(0, 0)}}.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+
+	// Rewrite the single record with two extra bytes, as a later version might add.
+	record := append(entry[2:], 0xAB, 0xCD)
+	data := binary.AppendUvarint([]byte{1}, uint64(len(record)))
+	data = append(data, record...)
+
+	var got Directory
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "a" || got[0].Size != 1 {
+		t.Errorf("UnmarshalBinary() = %+v, want entry \"a\" of 1 byte", got)
+	}
+}
@@ -0,0 +1,225 @@
+// Package container defines the on-disk layout of `.vlc` files. Every file
+// starts with a header carrying magic bytes, the format version and the
+// identifier of the codec (see the codec package) that produced the payload, so
+// readers can validate the file before decoding it, and optionally the original
+// file name. The payload that follows is a sequence of independently encoded
+// blocks, described in block.go.
+package container
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// Version is the current format version written by packers. Version 2 moved the
+	// symbol count from the header into the codec's own stream; version 3 split the
+	// payload into blocks and moved the original length into the trailer; version 4
+	// added CRC32C checksums to every block and trailer.
+	Version = 4
+
+	// HeaderSize is the encoded size of Header in bytes without a name:
+	// magic (4) + version (1) + codec (1) + flags (1). See Header.Size.
+	HeaderSize = len(magic) + 3
+
+	// MaxNameSize is the longest Header.Name in bytes, the file name limit of
+	// common file systems.
+	MaxNameSize = 255
+)
+
+// FlagArchive marks a multi-file archive: the header is followed by the payload of
+// every entry in turn and then by the central directory (see directory.go). Without
+// it the header is followed by the payload of a single, unnamed file.
+const FlagArchive uint8 = 1 << 0
+
+// FlagSHA256 marks streams whose payload trailers also carry the SHA-256 digest
+// of the original data, see Trailer.
+const FlagSHA256 uint8 = 1 << 1
+
+// FlagName marks headers followed by the original file name of a single-file
+// stream as a uvarint length and that many bytes, see Header.Name. It is set by
+// MarshalBinary whenever the name is not empty.
+const FlagName uint8 = 1 << 2
+
+// knownFlags is the mask of flag bits understood by this version of the format.
+// Readers reject files with other bits set rather than silently misinterpreting them.
+const knownFlags = FlagArchive | FlagSHA256 | FlagName
+
+// magic identifies a `.vlc` file.
+var magic = [4]byte{'S', 'V', 'L', 'C'}
+
+var (
+	// ErrBadMagic is returned when the data does not start with the `.vlc` magic bytes.
+	ErrBadMagic = errors.New("not a vlc file: bad magic bytes")
+	// ErrUnsupportedVersion is returned for format versions this reader cannot handle.
+	ErrUnsupportedVersion = errors.New("unsupported format version")
+	// ErrTruncated is returned when the data ends inside a header, block or trailer.
+	ErrTruncated = errors.New("truncated data")
+	// ErrChecksum is returned when unpacked data does not match its recorded checksum.
+	ErrChecksum = errors.New("checksum mismatch")
+)
+
+// Header describes the payload that follows it in a `.vlc` file.
+type Header struct {
+	Version uint8  // Format version, see Version
+	Codec   uint8  // Codec identifier, see codec.Codec.ID
+	Flags   uint8  // Feature bits, see FlagArchive, FlagSHA256 and FlagName
+	Name    string // Original file name without directories, or empty; see FlagName
+}
+
+// NewHeader returns a header for the current format version.
+func NewHeader(codec uint8) Header {
+	return Header{
+		Version: Version,
+		Codec:   codec,
+	}
+}
+
+// MarshalBinary encodes the header into its on-disk form of Size bytes.
+// Returns an error if the name is not a valid file name; see ValidName.
+func (h Header) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, HeaderSize, h.Size())
+	copy(buf, magic[:])
+	buf[4] = h.Version
+	buf[5] = h.Codec
+	buf[6] = h.Flags &^ FlagName
+	if h.Name != "" {
+		if !ValidName(h.Name) {
+			return nil, fmt.Errorf("invalid file name %q", h.Name)
+		}
+		buf[6] |= FlagName
+		buf = binary.AppendUvarint(buf, uint64(len(h.Name)))
+		buf = append(buf, h.Name...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes and validates a header from the start of data.
+// Returns an error for bad magic bytes, unknown versions or flags, or an invalid
+// name. The codec identifier is not checked here; see codec.ByID.
+func (h *Header) UnmarshalBinary(data []byte) error {
+	if len(data) < HeaderSize {
+		return fmt.Errorf("%w: header needs %d bytes, got %d", ErrTruncated, HeaderSize, len(data))
+	}
+	if !bytes.Equal(data[:len(magic)], magic[:]) {
+		return ErrBadMagic
+	}
+
+	parsed := Header{
+		Version: data[4],
+		Codec:   data[5],
+		Flags:   data[6],
+	}
+
+	if parsed.Version != Version {
+		return fmt.Errorf("%w: %d (supported: %d)", ErrUnsupportedVersion, parsed.Version, Version)
+	}
+	if parsed.Flags&^knownFlags != 0 {
+		return fmt.Errorf("unsupported header flags: %08b", parsed.Flags&^knownFlags)
+	}
+
+	if parsed.Flags&FlagName != 0 {
+		length, n := binary.Uvarint(data[HeaderSize:])
+		switch {
+		case n == 0:
+			return fmt.Errorf("%w: header ends before the file name", ErrTruncated)
+		case n < 0 || length == 0 || length > MaxNameSize:
+			return errors.New("invalid file name length in header")
+		case uint64(len(data)-HeaderSize-n) < length:
+			return fmt.Errorf("%w: header ends inside the file name", ErrTruncated)
+		}
+		parsed.Name = string(data[HeaderSize+n : HeaderSize+n+int(length)])
+		if !ValidName(parsed.Name) {
+			return fmt.Errorf("invalid file name %q in header", parsed.Name)
+		}
+	}
+
+	*h = parsed
+	return nil
+}
+
+// Size returns the encoded size of the header in bytes: HeaderSize plus the
+// length-prefixed name, if any.
+func (h Header) Size() int {
+	if h.Name == "" {
+		return HeaderSize
+	}
+	return HeaderSize + len(binary.AppendUvarint(nil, uint64(len(h.Name)))) + len(h.Name)
+}
+
+// ValidName reports whether name can be stored as Header.Name: a single file
+// name of at most MaxNameSize bytes, without directories, that is neither "."
+// nor "..".
+func ValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && len(name) <= MaxNameSize &&
+		!strings.ContainsAny(name, "/\\\x00")
+}
+
+// TrailerSize returns the encoded size of the payload trailers of the stream.
+func (h Header) TrailerSize() int {
+	if h.Flags&FlagSHA256 != 0 {
+		return TrailerSize + sha256.Size
+	}
+	return TrailerSize
+}
+
+// ReadHeader reads and validates a header, including its name, from the start of
+// r. When r is not an io.ByteReader, no more than the header is consumed.
+func ReadHeader(r io.Reader) (Header, error) {
+	buf := make([]byte, HeaderSize)
+	if n, err := io.ReadFull(r, buf); err != nil {
+		return Header{}, truncatedHeader(err, n)
+	}
+
+	if buf[6]&FlagName != 0 {
+		// Read the length a byte at a time so nothing after the name is consumed.
+		br, ok := r.(io.ByteReader)
+		if !ok {
+			br = byteReader{r}
+		}
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return Header{}, truncatedHeader(err, len(buf))
+		}
+		if length == 0 || length > MaxNameSize {
+			return Header{}, errors.New("invalid file name length in header")
+		}
+		buf = binary.AppendUvarint(buf, length)
+		name := make([]byte, length)
+		if n, err := io.ReadFull(r, name); err != nil {
+			return Header{}, truncatedHeader(err, len(buf)+n)
+		}
+		buf = append(buf, name...)
+	}
+
+	var h Header
+	if err := h.UnmarshalBinary(buf); err != nil {
+		return Header{}, err
+	}
+	return h, nil
+}
+
+// truncatedHeader reports running out of data after n bytes of a header as
+// ErrTruncated and wraps other read errors.
+func truncatedHeader(err error, n int) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: header ends after %d bytes", ErrTruncated, n)
+	}
+	return fmt.Errorf("read header: %w", err)
+}
+
+// byteReader reads single bytes from an io.Reader without reading ahead.
+type byteReader struct {
+	io.Reader
+}
+
+func (b byteReader) ReadByte() (byte, error) {
+	var c [1]byte
+	_, err := io.ReadFull(b.Reader, c[:])
+	return c[0], err
+}
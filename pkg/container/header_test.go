@@ -0,0 +1,59 @@
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHeaderNameRoundTrip(t *testing.T) {
+	for _, name := range []string{"", "report.md", "data.csv", "ünïcødé.txt", strings.Repeat("n", MaxNameSize)} {
+		h := NewHeader(1)
+		h.Name = name
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(%q) failed: %v", name, err)
+		}
+		if len(data) != h.Size() {
+			t.Errorf("MarshalBinary(%q) wrote %d bytes, Size() = %d", name, len(data), h.Size())
+		}
+
+		// ReadHeader must leave the payload after the name unread.
+		r := struct{ io.Reader }{bytes.NewReader(append(data, "payload"...))}
+		got, err := ReadHeader(r)
+		if err != nil {
+			t.Fatalf("ReadHeader(%q) failed: %v", name, err)
+		}
+		if got.Name != name || got.Codec != 1 || (got.Flags&FlagName != 0) != (name != "") {
+			t.Errorf("ReadHeader() = %+v, want name %q", got, name)
+		}
+		if rest, _ := io.ReadAll(r); string(rest) != "payload" {
+			t.Errorf("ReadHeader(%q) left %q unread, want %q", name, rest, "payload")
+		}
+	}
+}
+
+func TestHeaderRejectsBadNames(t *testing.T) {
+	for _, name := range []string{".", "..", "dir/file", `dir\file`, "nul\x00", strings.Repeat("n", MaxNameSize+1)} {
+		if _, err := (Header{Version: Version, Name: name}).MarshalBinary(); err == nil {
+			t.Errorf("MarshalBinary() with name %q succeeded, want error", name)
+		}
+
+		// A header written by hand with the bad name must be rejected on read.
+		data, _ := NewHeader(0).MarshalBinary()
+		data[6] |= FlagName
+		data = binary.AppendUvarint(data, uint64(len(name)))
+		data = append(data, name...)
+		if _, err := ReadHeader(bytes.NewReader(data)); err == nil {
+			t.Errorf("ReadHeader() with name %q succeeded, want error", name)
+		}
+	}
+
+	data, _ := Header{Version: Version, Name: "cut.txt"}.MarshalBinary()
+	if _, err := ReadHeader(bytes.NewReader(data[:len(data)-2])); !errors.Is(err, ErrTruncated) {
+		t.Errorf("ReadHeader() of a cut name: error = %v, want %v", err, ErrTruncated)
+	}
+}
@@ -1,9 +1,11 @@
 package decodingTree
 
 import (
-	"errors"
 	"fmt"
-	"strings"
+	"io"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
 )
 
 type DecodingTree struct {
@@ -12,38 +14,39 @@ type DecodingTree struct {
 	One   *DecodingTree
 }
 
-func BuildDecodingTree(table map[rune]string) (*DecodingTree, error) {
+func BuildDecodingTree(codes table.CodeTable) (*DecodingTree, error) {
 	root := &DecodingTree{}
 
-	for char, code := range table {
+	for char, code := range codes {
+		if code.Length == 0 || code.Length > 64 {
+			return nil, fmt.Errorf("invalid code length %d for '%c'", code.Length, char)
+		}
+
 		current := root
-		for i, bit := range code {
-			switch bit {
-			case '0':
+		for i := int(code.Length) - 1; i >= 0; i-- {
+			if code.Bits>>i&1 == 0 {
 				if current.Zero == nil {
 					current.Zero = &DecodingTree{}
 				}
 				current = current.Zero
-			case '1':
+			} else {
 				if current.One == nil {
 					current.One = &DecodingTree{}
 				}
 				current = current.One
-			default:
-				return nil, fmt.Errorf("invalid bit '%c' in code for '%c'", bit, char)
 			}
 
-			if i < len(code)-1 && current.Value != nil {
-				return nil, fmt.Errorf("code conflict: '%s' is prefix of '%c'", code[:i+1], *current.Value)
+			if i > 0 && current.Value != nil {
+				return nil, fmt.Errorf("code conflict: code of '%c' is prefix of %v", *current.Value, code)
 			}
 		}
 
 		if current.Zero != nil || current.One != nil {
-			return nil, fmt.Errorf("code '%s' is prefix of another code", code)
+			return nil, fmt.Errorf("code %v is prefix of another code", code)
 		}
 
 		if current.Value != nil {
-			return nil, fmt.Errorf("duplicate code '%s'", code)
+			return nil, fmt.Errorf("duplicate code %v", code)
 		}
 
 		current.Value = &char
@@ -52,35 +55,52 @@ func BuildDecodingTree(table map[rune]string) (*DecodingTree, error) {
 	return root, nil
 }
 
-func (dt *DecodingTree) Decode(encoded string) (string, error) {
-	var builder strings.Builder
-	current := dt
+// ReadSymbol reads bits from br one at a time, following the tree until they
+// form a complete code, and returns its symbol. Returns io.EOF if br had no bits
+// left and io.ErrUnexpectedEOF if the data ended inside a code.
+func (dt *DecodingTree) ReadSymbol(br *chunks.BitReader) (rune, error) {
+	return dt.readFrom(dt, br)
+}
 
-	for pos, bit := range encoded {
-		switch bit {
-		case '0':
-			if current.Zero == nil {
-				return "", fmt.Errorf("unexpected 0 at position %d", pos)
-			}
-			current = current.Zero
-		case '1':
-			if current.One == nil {
-				return "", fmt.Errorf("unexpected 1 at position %d", pos)
+// readFrom continues decoding from node, which is dt itself or a node part way
+// down a code, until the bits read form a complete code.
+func (dt *DecodingTree) readFrom(node *DecodingTree, br *chunks.BitReader) (rune, error) {
+	current := node
+
+	for {
+		bit, available, err := br.PeekBits(1)
+		if err != nil {
+			return 0, err
+		}
+		if available == 0 {
+			if current != dt {
+				return 0, io.ErrUnexpectedEOF
 			}
-			current = current.One
-		default:
-			return "", fmt.Errorf("invalid bit '%c' at position %d", bit, pos)
+			return 0, io.EOF
+		}
+		br.SkipBits(1)
+
+		next := current.Zero
+		if bit == 1 {
+			next = current.One
 		}
+		if next == nil {
+			return 0, fmt.Errorf("unexpected %d: no code matches", bit)
+		}
+		current = next
 
 		if current.Value != nil {
-			builder.WriteRune(*current.Value)
-			current = dt
+			return *current.Value, nil
 		}
 	}
+}
 
-	if current != dt {
-		return "", errors.New("incomplete encoding")
+// BuildFromLengths rebuilds the decoding tree of a canonical prefix code from the
+// code lengths alone, as stored by table.MarshalLengths.
+func BuildFromLengths(lengths table.CodeLengths) (*DecodingTree, error) {
+	codes, err := lengths.CanonicalCodes()
+	if err != nil {
+		return nil, fmt.Errorf("canonical codes: %w", err)
 	}
-
-	return builder.String(), nil
+	return BuildDecodingTree(codes)
 }
@@ -1,38 +1,89 @@
 package decodingTree
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
 )
 
 func TestBuildDecodingTree(t *testing.T) {
 	tests := []struct {
 		name    string
-		ec      map[rune]string
+		codes   table.CodeTable
 		wantErr bool
 	}{
 		{
 			name:    "valid codes",
-			ec:      map[rune]string{'a': "0", 'b': "1"},
+			codes:   table.CodeTable{'a': {Bits: 0b0, Length: 1}, 'b': {Bits: 0b1, Length: 1}},
 			wantErr: false,
 		},
 		{
-			name:    "invalid code character",
-			ec:      map[rune]string{'a': "2"},
+			name:    "empty code",
+			codes:   table.CodeTable{'a': {Bits: 0, Length: 0}},
 			wantErr: true,
 		},
 		{
 			name:    "conflicting codes",
-			ec:      map[rune]string{'a': "0", 'b': "01"},
+			codes:   table.CodeTable{'a': {Bits: 0b0, Length: 1}, 'b': {Bits: 0b01, Length: 2}},
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := BuildDecodingTree(tt.ec)
+			_, err := BuildDecodingTree(tt.codes)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BuildDecodingTree() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestReadSymbolIgnoresPadding(t *testing.T) {
+	tree, err := BuildDecodingTree(table.CodeTable{'e': {Bits: 0b000, Length: 3}, 't': {Bits: 0b0010, Length: 4}})
+	if err != nil {
+		t.Fatalf("BuildDecodingTree() failed: %v", err)
+	}
+
+	// "te" followed by one bit of zero padding, which would start another 'e'.
+	br := chunks.NewBitReader(bytes.NewReader([]byte{0b00100000}))
+	for _, want := range "te" {
+		got, err := tree.ReadSymbol(br)
+		if err != nil {
+			t.Fatalf("ReadSymbol() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("ReadSymbol() = %q, want %q", got, want)
+		}
+	}
+	if br.Buffered() != 1 {
+		t.Errorf("Buffered() = %d, want 1 bit of padding", br.Buffered())
+	}
+
+	if _, err := tree.ReadSymbol(br); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadSymbol() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestBuildFromLengths(t *testing.T) {
+	tree, err := BuildFromLengths(table.CodeLengths{'a': 1, 'b': 2, 'c': 2})
+	if err != nil {
+		t.Fatalf("BuildFromLengths() failed: %v", err)
+	}
+
+	// Canonical codes: a = 0, b = 10, c = 11, followed by three bits of padding.
+	br := chunks.NewBitReader(bytes.NewReader([]byte{0b01011000}))
+	for _, want := range "abc" {
+		got, err := tree.ReadSymbol(br)
+		if err != nil {
+			t.Fatalf("ReadSymbol() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("ReadSymbol() = %q, want %q", got, want)
+		}
+	}
+}
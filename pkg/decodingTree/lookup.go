@@ -0,0 +1,111 @@
+package decodingTree
+
+import (
+	"fmt"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+const (
+	// DefaultLookupBits is the number of bits a LookupTable resolves per step
+	// unless told otherwise: 1024 entries, enough for every code of the fixed
+	// table in one step.
+	DefaultLookupBits = 10
+
+	// MaxLookupBits bounds the table size at 2^MaxLookupBits entries.
+	MaxLookupBits = 16
+)
+
+// LookupTable decodes a prefix code by peeking a fixed number of bits at a time
+// and looking them up, instead of following one tree pointer per bit. Codes no
+// longer than the lookup width resolve in a single step; longer codes resolve
+// their first bits in the table and continue down the DecodingTree they were
+// derived from.
+type LookupTable struct {
+	bits    uint
+	entries []lookupEntry
+	tree    *DecodingTree
+}
+
+// lookupEntry describes every bit window starting with one prefix. A complete
+// code shorter than the window has length set and node nil; a window that is
+// only the start of a longer code points at the tree node it reaches. Windows
+// that match no code have neither and are left to the tree to report.
+type lookupEntry struct {
+	symbol rune
+	length uint8
+	node   *DecodingTree
+}
+
+// NewLookupTable builds a table resolving up to bits bits per step from the
+// given tree. bits must be between 1 and MaxLookupBits.
+func NewLookupTable(tree *DecodingTree, bits uint) (*LookupTable, error) {
+	if bits < 1 || bits > MaxLookupBits {
+		return nil, fmt.Errorf("lookup width %d out of range 1..%d", bits, MaxLookupBits)
+	}
+
+	lt := &LookupTable{bits: bits, entries: make([]lookupEntry, 1<<bits), tree: tree}
+	lt.fill(tree, 0, 0)
+	return lt, nil
+}
+
+// BuildLookupTable builds the decoding tree for a code table and a lookup table
+// of DefaultLookupBits over it.
+func BuildLookupTable(codes table.CodeTable) (*LookupTable, error) {
+	tree, err := BuildDecodingTree(codes)
+	if err != nil {
+		return nil, err
+	}
+	return NewLookupTable(tree, DefaultLookupBits)
+}
+
+// fill walks the tree below node, which is reached by the depth bits of prefix,
+// and records every leaf and every node at the lookup width in the table.
+func (lt *LookupTable) fill(node *DecodingTree, prefix uint64, depth uint) {
+	if node == nil {
+		return
+	}
+
+	if node.Value != nil || depth == lt.bits {
+		entry := lookupEntry{length: uint8(depth), node: node}
+		if node.Value != nil {
+			entry.symbol, entry.node = *node.Value, nil
+		}
+		first := prefix << (lt.bits - depth)
+		for i := range uint64(1) << (lt.bits - depth) {
+			lt.entries[first+i] = entry
+		}
+		return
+	}
+
+	lt.fill(node.Zero, prefix<<1, depth+1)
+	lt.fill(node.One, prefix<<1|1, depth+1)
+}
+
+// Tree returns the decoding tree the table was built from.
+func (lt *LookupTable) Tree() *DecodingTree {
+	return lt.tree
+}
+
+// ReadSymbol reads the next code from br and returns its symbol, with the same
+// results and errors as DecodingTree.ReadSymbol.
+func (lt *LookupTable) ReadSymbol(br *chunks.BitReader) (rune, error) {
+	window, available, err := br.PeekBits(lt.bits)
+	if err != nil {
+		return 0, err
+	}
+
+	entry := &lt.entries[window]
+	if entry.node == nil && entry.length > 0 && uint(entry.length) <= available {
+		br.SkipBits(uint(entry.length))
+		return entry.symbol, nil
+	}
+	if entry.node != nil && available == lt.bits {
+		br.SkipBits(lt.bits)
+		return lt.tree.readFrom(entry.node, br)
+	}
+	// No code matches, or the data ends inside the window: let the tree walk
+	// bit by bit and report exactly where decoding fails.
+	return lt.tree.ReadSymbol(br)
+}
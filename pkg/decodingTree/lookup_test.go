@@ -0,0 +1,182 @@
+package decodingTree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+// longCodeLengths is a valid prefix code whose longest codes exceed
+// DefaultLookupBits, so decoding them falls back to the tree.
+var longCodeLengths = table.CodeLengths{
+	'a': 1, 'b': 2, 'c': 3, 'd': 4, 'e': 5, 'f': 6, 'g': 7, 'h': 8,
+	'i': 9, 'j': 10, 'k': 11, 'l': 12, 'm': 13, 'n': 14, 'o': 15, 'p': 15,
+}
+
+// packRandom returns n symbols drawn from codes and the bits encoding them.
+func packRandom(t testing.TB, codes table.CodeTable, n int) ([]rune, []byte) {
+	t.Helper()
+
+	symbols := make([]rune, 0, len(codes))
+	for symbol := range codes {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+
+	rng := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	bw := chunks.NewBitWriter(&buf)
+	want := make([]rune, n)
+	for i := range want {
+		want[i] = symbols[rng.Intn(len(symbols))]
+		code := codes[want[i]]
+		if err := bw.WriteBits(code.Bits, uint(code.Length)); err != nil {
+			t.Fatalf("WriteBits() failed: %v", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	return want, buf.Bytes()
+}
+
+// symbolReader is implemented by both DecodingTree and LookupTable.
+type symbolReader interface {
+	ReadSymbol(br *chunks.BitReader) (rune, error)
+}
+
+// readAll decodes n symbols from packed.
+func readAll(t testing.TB, sr symbolReader, packed []byte, n int) []rune {
+	t.Helper()
+
+	br := chunks.NewBitReader(bytes.NewReader(packed))
+	got := make([]rune, n)
+	for i := range got {
+		symbol, err := sr.ReadSymbol(br)
+		if err != nil {
+			t.Fatalf("symbol %d: ReadSymbol() failed: %v", i, err)
+		}
+		got[i] = symbol
+	}
+	return got
+}
+
+func TestLookupTableMatchesTree(t *testing.T) {
+	fixed := table.BuildCodeTable()
+	long, err := longCodeLengths.CanonicalCodes()
+	if err != nil {
+		t.Fatalf("CanonicalCodes() failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		codes table.CodeTable
+	}{
+		{"fixed table", fixed},
+		{"long codes", long},
+	} {
+		tree, err := BuildDecodingTree(tt.codes)
+		if err != nil {
+			t.Fatalf("%s: BuildDecodingTree() failed: %v", tt.name, err)
+		}
+		want, packed := packRandom(t, tt.codes, 5000)
+
+		for _, bits := range []uint{1, 4, DefaultLookupBits, MaxLookupBits} {
+			lt, err := NewLookupTable(tree, bits)
+			if err != nil {
+				t.Fatalf("%s: NewLookupTable(%d) failed: %v", tt.name, bits, err)
+			}
+			if got := readAll(t, lt, packed, len(want)); !slices.Equal(got, want) {
+				t.Errorf("%s: %d-bit lookup decoded different symbols than were packed", tt.name, bits)
+			}
+		}
+		if got := readAll(t, tree, packed, len(want)); !slices.Equal(got, want) {
+			t.Errorf("%s: tree decoded different symbols than were packed", tt.name)
+		}
+	}
+}
+
+func TestLookupTableErrors(t *testing.T) {
+	// 'a' = 0, 'b' = 10; the prefix 11 matches no code.
+	lt, err := BuildLookupTable(table.CodeTable{'a': {Bits: 0b0, Length: 1}, 'b': {Bits: 0b10, Length: 2}})
+	if err != nil {
+		t.Fatalf("BuildLookupTable() failed: %v", err)
+	}
+
+	br := chunks.NewBitReader(bytes.NewReader([]byte{0b01100000}))
+	if got, err := lt.ReadSymbol(br); err != nil || got != 'a' {
+		t.Fatalf("ReadSymbol() = %q, %v, want 'a'", got, err)
+	}
+	if _, err := lt.ReadSymbol(br); err == nil {
+		t.Error("ReadSymbol() on an unmatched prefix succeeded, want error")
+	}
+
+	// A single 1 bit followed by nothing: the data ends inside 'b'.
+	tree := lt.Tree()
+	for _, sr := range []symbolReader{lt, tree} {
+		br := chunks.NewBitReader(bytes.NewReader([]byte{0b00000001}))
+		for range 7 {
+			if _, err := sr.ReadSymbol(br); err != nil {
+				t.Fatalf("ReadSymbol() failed: %v", err)
+			}
+		}
+		if _, err := sr.ReadSymbol(br); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%T.ReadSymbol() error = %v, want %v", sr, err, io.ErrUnexpectedEOF)
+		}
+		if _, err := sr.ReadSymbol(br); !errors.Is(err, io.EOF) {
+			t.Errorf("%T.ReadSymbol() at end error = %v, want %v", sr, err, io.EOF)
+		}
+	}
+
+	if _, err := NewLookupTable(tree, MaxLookupBits+1); err == nil {
+		t.Errorf("NewLookupTable(%d) succeeded, want error", MaxLookupBits+1)
+	}
+}
+
+// benchmarkReadSymbol decodes random codes of the fixed table, or of lengths when
+// given, with a lookup table of lookupBits, or with the tree walker when lookupBits
+// is 0. It first checks the lookup table yields the same symbols as the tree.
+func benchmarkReadSymbol(b *testing.B, lengths table.CodeLengths, lookupBits uint) {
+	codes := table.BuildCodeTable()
+	if lengths != nil {
+		var err error
+		if codes, err = lengths.CanonicalCodes(); err != nil {
+			b.Fatalf("CanonicalCodes() failed: %v", err)
+		}
+	}
+	tree, err := BuildDecodingTree(codes)
+	if err != nil {
+		b.Fatalf("BuildDecodingTree() failed: %v", err)
+	}
+
+	var sr symbolReader = tree
+	if lookupBits > 0 {
+		if sr, err = NewLookupTable(tree, lookupBits); err != nil {
+			b.Fatalf("NewLookupTable() failed: %v", err)
+		}
+	}
+
+	want, packed := packRandom(b, codes, 100000)
+	if got := readAll(b, sr, packed, len(want)); !slices.Equal(got, readAll(b, tree, packed, len(want))) {
+		b.Fatal("decoded symbols differ from the tree walker's")
+	}
+
+	b.SetBytes(int64(len(packed)))
+	b.ResetTimer()
+	for range b.N {
+		readAll(b, sr, packed, len(want))
+	}
+}
+
+func BenchmarkTreeFixedTable(b *testing.B)   { benchmarkReadSymbol(b, nil, 0) }
+func BenchmarkLookupFixedTable(b *testing.B) { benchmarkReadSymbol(b, nil, DefaultLookupBits) }
+func BenchmarkTreeLongCodes(b *testing.B)    { benchmarkReadSymbol(b, longCodeLengths, 0) }
+func BenchmarkLookupLongCodes(b *testing.B) {
+	benchmarkReadSymbol(b, longCodeLengths, DefaultLookupBits)
+}
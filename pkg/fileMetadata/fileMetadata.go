@@ -0,0 +1,112 @@
+// Package fileMetadata reads and restores the file metadata recorded in archive
+// entries beyond what fs.FileInfo offers portably: access time, ownership and
+// extended attributes. Where a platform does not support a kind of metadata it
+// is not recorded, and restoring it is a no-op.
+package fileMetadata
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// Read fills in the access time, owner and group of e from info, the result of
+// os.Lstat on path, and with xattrs also the extended attributes of the file.
+func Read(e *container.Entry, path string, info fs.FileInfo, xattrs bool) error {
+	if readStat(e, info) {
+		e.User = names.user(e.UID)
+		e.Group = names.group(e.GID)
+	}
+
+	if !xattrs || info.Mode()&fs.ModeSymlink != 0 {
+		return nil
+	}
+	attrs, err := listXattrs(path)
+	if err != nil {
+		return fmt.Errorf("read extended attributes: %w", err)
+	}
+	e.Xattrs = attrs
+	return nil
+}
+
+// Chown changes the owner and group of path, without following a symlink, to
+// those of e. The user and group names are preferred when they exist on this
+// system, so that archives move between systems with different numeric IDs.
+func Chown(path string, e container.Entry) error {
+	uid, gid := int(e.UID), int(e.GID)
+	if e.User != "" {
+		if u, err := user.Lookup(e.User); err == nil {
+			if id, err := strconv.Atoi(u.Uid); err == nil {
+				uid = id
+			}
+		}
+	}
+	if e.Group != "" {
+		if g, err := user.LookupGroup(e.Group); err == nil {
+			if id, err := strconv.Atoi(g.Gid); err == nil {
+				gid = id
+			}
+		}
+	}
+	return os.Lchown(path, uid, gid)
+}
+
+// SetXattrs sets the extended attributes of e on path.
+func SetXattrs(path string, e container.Entry) error {
+	for name, value := range e.Xattrs {
+		if err := setXattr(path, name, value); err != nil {
+			return fmt.Errorf("set extended attribute %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Umask returns the process umask, the permission bits removed from new files.
+func Umask() fs.FileMode {
+	return umask()
+}
+
+// nameCache remembers user and group name lookups, which may read /etc/passwd
+// or query a directory service, across the entries of an archive.
+type nameCache struct {
+	mu     sync.Mutex
+	users  map[uint32]string
+	groups map[uint32]string
+}
+
+var names = nameCache{users: make(map[uint32]string), groups: make(map[uint32]string)}
+
+// user returns the name of the user with the given ID, or "" if it is unknown.
+func (c *nameCache) user(uid uint32) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, ok := c.users[uid]
+	if !ok {
+		if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+			name = u.Username
+		}
+		c.users[uid] = name
+	}
+	return name
+}
+
+// group returns the name of the group with the given ID, or "" if it is unknown.
+func (c *nameCache) group(gid uint32) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, ok := c.groups[gid]
+	if !ok {
+		if g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10)); err == nil {
+			name = g.Name
+		}
+		c.groups[gid] = name
+	}
+	return name
+}
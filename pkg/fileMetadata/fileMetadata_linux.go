@@ -0,0 +1,66 @@
+package fileMetadata
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// readStat takes the access time and owner of e from the Stat_t behind info and
+// reports whether there was one.
+func readStat(e *container.Entry, info fs.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	e.AccessTime = time.Unix(st.Atim.Unix())
+	e.UID, e.GID = st.Uid, st.Gid
+	return true
+}
+
+// listXattrs returns every extended attribute of path, or nil if the file
+// system does not support them.
+func listXattrs(path string) (map[string][]byte, error) {
+	size, err := syscall.Listxattr(path, nil)
+	if errors.Is(err, syscall.ENOTSUP) {
+		return nil, nil
+	}
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	list := make([]byte, size)
+	if size, err = syscall.Listxattr(path, list); err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string][]byte)
+	for _, name := range bytes.Split(bytes.TrimSuffix(list[:size], []byte{0}), []byte{0}) {
+		size, err := syscall.Getxattr(path, string(name), nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size)
+		if size, err = syscall.Getxattr(path, string(name), value); err != nil {
+			return nil, err
+		}
+		attrs[string(name)] = value[:size]
+	}
+	return attrs, nil
+}
+
+// setXattr sets one extended attribute of path.
+func setXattr(path, name string, value []byte) error {
+	return syscall.Setxattr(path, name, value, 0)
+}
+
+// umask reads the umask once; reading it requires briefly setting it.
+var umask = sync.OnceValue(func() fs.FileMode {
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	return fs.FileMode(mask)
+})
@@ -0,0 +1,30 @@
+//go:build !linux
+
+package fileMetadata
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// readStat records nothing: access times and owners are only read on Linux.
+func readStat(e *container.Entry, info fs.FileInfo) bool {
+	return false
+}
+
+// listXattrs records no extended attributes: they are only read on Linux.
+func listXattrs(path string) (map[string][]byte, error) {
+	return nil, nil
+}
+
+// setXattr fails: extended attributes are only restored on Linux.
+func setXattr(path, name string, value []byte) error {
+	return errors.New("extended attributes are not supported on this platform")
+}
+
+// umask assumes the common default, as it cannot be read portably.
+func umask() fs.FileMode {
+	return 0022
+}
@@ -0,0 +1,263 @@
+// Package huffman implements a static Huffman codec over bytes. The code table is
+// built from the symbol frequencies of the input, limited to table.MaxCodeLength bits
+// and put into canonical form, so only the code length of each byte value has to be
+// stored alongside the packed data for the decoder to rebuild the same table.
+package huffman
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+const (
+	// alphabetSize is the number of distinct symbols: one per byte value.
+	alphabetSize = table.ByteAlphabetSize
+
+	// TableSize is the size in bytes of the serialised code lengths that start
+	// an encoded stream: two 4-bit lengths per byte.
+	TableSize = alphabetSize / 2
+
+	// outputBufferSize is the amount of decoded data Decode collects before
+	// writing it out.
+	outputBufferSize = 64 << 10
+)
+
+// Encode reads src to the end, builds a Huffman table for it and writes the
+// serialised code lengths, the number of bytes as a uvarint and the packed codes
+// to dst. The final byte is padded with zero bits.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading, packing or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+
+	lengths := BuildLengths(data)
+	canonical, err := lengths.CanonicalCodes()
+	if err != nil {
+		return fmt.Errorf("build canonical table: %w", err)
+	}
+	serialised, err := table.MarshalLengths(lengths, alphabetSize)
+	if err != nil {
+		return fmt.Errorf("marshal code lengths: %w", err)
+	}
+
+	var codes [alphabetSize]table.Code
+	for symbol, code := range canonical {
+		codes[symbol] = code
+	}
+
+	var packed bytes.Buffer
+	bw := chunks.NewBitWriter(&packed)
+	for _, b := range data {
+		if err := bw.WriteBits(codes[b].Bits, uint(codes[b].Length)); err != nil {
+			return fmt.Errorf("pack codes: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("pack codes: %w", err)
+	}
+
+	serialised = binary.AppendUvarint(serialised, uint64(len(data)))
+	if _, err := dst.Write(serialised); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	if _, err := packed.WriteTo(dst); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, rebuilds the Huffman table from the
+// code lengths at its start and writes exactly the recorded number of decoded
+// bytes to dst. Only the zero bits padding the final byte may follow the last code.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the table is invalid, the data is truncated or corrupt,
+//     or writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+
+	serialised := make([]byte, TableSize)
+	if _, err := io.ReadFull(br, serialised); err != nil {
+		return fmt.Errorf("read code lengths: %w", err)
+	}
+	lengths, _, err := table.UnmarshalLengths(serialised, alphabetSize)
+	if err != nil {
+		return fmt.Errorf("read code lengths: %w", err)
+	}
+	tree, err := decodingTree.BuildFromLengths(lengths)
+	if err != nil {
+		return fmt.Errorf("build decoding tree: %w", err)
+	}
+	lookup, err := decodingTree.NewLookupTable(tree, decodingTree.DefaultLookupBits)
+	if err != nil {
+		return fmt.Errorf("build decoding table: %w", err)
+	}
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return errors.New("invalid byte count")
+	}
+	if limit := chunks.Remaining(dst); count > uint64(limit) {
+		return fmt.Errorf("byte count %d: %w", count, chunks.ErrLimit)
+	}
+
+	out := make([]byte, 0, min(count, outputBufferSize))
+	bits := chunks.NewBitReader(br)
+	for decoded := uint64(0); decoded < count; decoded++ {
+		symbol, err := lookup.ReadSymbol(bits)
+		if err != nil {
+			return fmt.Errorf("symbol %d: %w", decoded, truncated(err))
+		}
+		if out = append(out, byte(symbol)); len(out) == cap(out) {
+			if _, err := dst.Write(out); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+			out = out[:0]
+		}
+	}
+
+	if padding := bits.Buffered(); padding >= chunks.ChunkSize {
+		return fmt.Errorf("unexpected %d trailing bits after last symbol", padding)
+	}
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last symbol")
+	}
+
+	if _, err := dst.Write(out); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// BuildLengths counts byte frequencies in data with table.CountBytes, the model
+// shared with the ANS codec, and returns the Huffman code length of every byte
+// value that occurs, as computed by LengthsFromFrequencies.
+func BuildLengths(data []byte) table.CodeLengths {
+	return LengthsFromFrequencies(table.CountBytes(data))
+}
+
+// LengthsFromFrequencies returns the Huffman code length of every symbol with a
+// non-zero frequency, for alphabets of any size. When the optimal code would
+// exceed table.MaxCodeLength, frequencies are halved until it no longer does.
+// freqs is left unchanged.
+func LengthsFromFrequencies(freqs table.Frequencies) table.CodeLengths {
+	freqs = slices.Clone(freqs)
+	for {
+		lengths, longest := codeLengths(freqs)
+		if longest <= table.MaxCodeLength {
+			return lengths
+		}
+		for i, f := range freqs {
+			if f > 0 {
+				freqs[i] = (f + 1) / 2
+			}
+		}
+	}
+}
+
+// node is a Huffman tree node. Leaves carry a symbol; internal nodes carry children.
+type node struct {
+	freq        uint64
+	order       int // tie-breaker keeping the tree, and so the output, deterministic
+	symbol      int
+	left, right *node
+}
+
+// nodeHeap is a min-heap of nodes ordered by frequency, then creation order.
+type nodeHeap []*node
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].order < h[j].order
+}
+func (h nodeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x interface{}) { *h = append(*h, x.(*node)) }
+func (h *nodeHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// codeLengths builds a Huffman tree for the non-zero frequencies and returns the
+// depth of every leaf together with the greatest depth. A lone symbol gets length 1.
+func codeLengths(freqs table.Frequencies) (table.CodeLengths, int) {
+	h := &nodeHeap{}
+	order := 0
+	for symbol, f := range freqs {
+		if f > 0 {
+			*h = append(*h, &node{freq: f, order: order, symbol: symbol})
+			order++
+		}
+	}
+
+	lengths := make(table.CodeLengths, h.Len())
+	switch h.Len() {
+	case 0:
+		return lengths, 0
+	case 1:
+		lengths[rune((*h)[0].symbol)] = 1
+		return lengths, 1
+	}
+
+	heap.Init(h)
+	for h.Len() > 1 {
+		left := heap.Pop(h).(*node)
+		right := heap.Pop(h).(*node)
+		heap.Push(h, &node{freq: left.freq + right.freq, order: order, left: left, right: right})
+		order++
+	}
+
+	longest := 0
+	var walk func(n *node, depth int)
+	walk = func(n *node, depth int) {
+		if n.left == nil {
+			if depth > longest {
+				longest = depth
+			}
+			if depth <= table.MaxCodeLength {
+				lengths[rune(n.symbol)] = uint8(depth)
+			}
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk((*h)[0], 0)
+
+	return lengths, longest
+}
+
+// truncated reports running out of data as io.ErrUnexpectedEOF, since the byte
+// count says more should follow.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
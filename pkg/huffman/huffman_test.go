@@ -0,0 +1,64 @@
+package huffman
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "single symbol", data: []byte("aaaaaaa")},
+		{name: "text", data: []byte("abracadabra, said the wizard\n")},
+		{name: "binary", data: []byte{0, 255, 0, 1, 2, 3, 255, 255, 128}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode(%q)) = %q", tt.data, decoded.Bytes())
+			}
+		})
+	}
+}
+
+func TestBuildLengthsLimitsCodeLength(t *testing.T) {
+	// Fibonacci frequencies produce the deepest possible Huffman tree.
+	var data []byte
+	a, b := 1, 1
+	for symbol := 0; symbol < 25; symbol++ {
+		data = append(data, bytes.Repeat([]byte{byte(symbol)}, a)...)
+		a, b = b, a+b
+	}
+
+	lengths := BuildLengths(data)
+	for symbol := rune(0); symbol < 25; symbol++ {
+		if lengths[symbol] == 0 || lengths[symbol] > table.MaxCodeLength {
+			t.Errorf("symbol %d has code length %d, want 1 to %d", symbol, lengths[symbol], table.MaxCodeLength)
+		}
+	}
+}
+
+func TestDecodeRejectsOversubscribedLengths(t *testing.T) {
+	lengths, err := table.MarshalLengths(table.CodeLengths{'a': 1, 'b': 1, 'c': 1}, alphabetSize)
+	if err != nil {
+		t.Fatalf("MarshalLengths() failed: %v", err)
+	}
+
+	stream := bytes.NewReader(append(lengths, 1, 0))
+	if err := Decode(&bytes.Buffer{}, stream); err == nil {
+		t.Errorf("Decode() expected error for oversubscribed code lengths")
+	}
+}
@@ -0,0 +1,371 @@
+// Package lz77 implements an LZ77 dictionary codec in the style of LZSS and
+// DEFLATE. A match finder over a sliding window, using hash chains, replaces
+// repeated strings with (length, distance) back-references; literals and matches
+// are then Huffman coded, so repeated words and lines cost a few bits each instead
+// of a code per character.
+//
+// Literals, match lengths and the end-of-stream symbol share one alphabet and
+// distances have another. Lengths and distances are sent as a bucket code followed
+// by extra bits, as in DEFLATE. An encoded stream is the window size in bits as a
+// byte, the code lengths of both alphabets (see table.MarshalLengths) and the
+// packed tokens, ended by the end-of-stream symbol and padded with zero bits.
+package lz77
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/huffman"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+const (
+	// MinMatch is the shortest back-reference; shorter repeats are sent as literals.
+	MinMatch = 3
+
+	// MaxMatch is the longest back-reference.
+	MaxMatch = 258
+
+	// MinWindowBits and MaxWindowBits bound the window size, 1<<WindowBits bytes.
+	MinWindowBits = 8
+	MaxWindowBits = 18
+
+	// DefaultWindowBits gives the 32 KiB window of DEFLATE.
+	DefaultWindowBits = 15
+
+	// endOfStream is the symbol closing every stream, after the 256 byte values.
+	endOfStream = 256
+
+	// lengthCodes is the number of bucket codes for match lengths, which follow
+	// endOfStream in the literal/length alphabet.
+	lengthCodes = 16
+
+	// literalAlphabetSize is the size of the literal/length alphabet.
+	literalAlphabetSize = endOfStream + 1 + lengthCodes
+
+	// distanceAlphabetSize is the number of bucket codes for distances up to
+	// 1<<MaxWindowBits.
+	distanceAlphabetSize = 2 * MaxWindowBits
+
+	// outputBufferSize is the amount of decoded data Decode collects before
+	// writing it out, on top of the window it keeps for matches to copy from.
+	outputBufferSize = 64 << 10
+)
+
+// Encoder encodes with a configurable window. The zero value is not valid; use
+// DefaultWindowBits unless told otherwise.
+type Encoder struct {
+	// WindowBits sets the window to 1<<WindowBits bytes, from MinWindowBits to
+	// MaxWindowBits. Larger windows find more distant repeats at some cost in speed.
+	WindowBits int
+}
+
+// Encode encodes src to dst with a window of 1<<DefaultWindowBits bytes.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading, packing or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	return Encoder{WindowBits: DefaultWindowBits}.Encode(dst, src)
+}
+
+// Encode reads src to the end, splits it into literals and back-references
+// within the window, builds Huffman tables for both alphabets and writes the
+// window size, the tables and the packed tokens to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if the window size is invalid, or reading, packing or
+//     writing fails.
+func (e Encoder) Encode(dst io.Writer, src io.Reader) error {
+	if err := ValidateWindowBits(e.WindowBits); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+
+	tokens := tokenize(data, 1<<e.WindowBits)
+
+	literalFreqs := make(table.Frequencies, literalAlphabetSize)
+	distanceFreqs := make(table.Frequencies, distanceAlphabetSize)
+	for _, t := range tokens {
+		if t.length == 0 {
+			literalFreqs[t.literal]++
+			continue
+		}
+		code, _, _ := bucket(uint32(t.length - MinMatch))
+		literalFreqs[endOfStream+1+code]++
+		code, _, _ = bucket(t.distance - 1)
+		distanceFreqs[code]++
+	}
+	literalFreqs[endOfStream]++
+
+	header := []byte{byte(e.WindowBits)}
+	literalCodes, header, err := appendTable(header, literalFreqs)
+	if err != nil {
+		return fmt.Errorf("literal table: %w", err)
+	}
+	distanceCodes, header, err := appendTable(header, distanceFreqs)
+	if err != nil {
+		return fmt.Errorf("distance table: %w", err)
+	}
+
+	var packed bytes.Buffer
+	bw := chunks.NewBitWriter(&packed)
+	write := func(code table.Code, extra uint, value uint32) error {
+		if err := bw.WriteBits(code.Bits, uint(code.Length)); err != nil {
+			return err
+		}
+		return bw.WriteBits(uint64(value), extra)
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			if err := write(literalCodes[rune(t.literal)], 0, 0); err != nil {
+				return fmt.Errorf("pack tokens: %w", err)
+			}
+			continue
+		}
+		code, extra, value := bucket(uint32(t.length - MinMatch))
+		if err := write(literalCodes[rune(endOfStream+1+code)], extra, value); err != nil {
+			return fmt.Errorf("pack tokens: %w", err)
+		}
+		code, extra, value = bucket(t.distance - 1)
+		if err := write(distanceCodes[rune(code)], extra, value); err != nil {
+			return fmt.Errorf("pack tokens: %w", err)
+		}
+	}
+	if err := write(literalCodes[endOfStream], 0, 0); err != nil {
+		return fmt.Errorf("pack tokens: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("pack tokens: %w", err)
+	}
+
+	if _, err := dst.Write(header); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	if _, err := packed.WriteTo(dst); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, rebuilds both Huffman tables and
+// writes the data the tokens describe to dst, up to the end-of-stream symbol.
+// Only the zero bits padding the final byte may follow it. For a
+// chunks.LimitedWriter, decoding stops with chunks.ErrLimit at the first token
+// past the limit.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the tables are invalid, the data is truncated or
+//     corrupt, or writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+
+	windowBits, err := br.ReadByte()
+	if err != nil {
+		return fmt.Errorf("read window size: %w", err)
+	}
+	if err := ValidateWindowBits(int(windowBits)); err != nil {
+		return err
+	}
+	window := 1 << windowBits
+
+	literals, err := readTable(br, literalAlphabetSize)
+	if err != nil {
+		return fmt.Errorf("literal table: %w", err)
+	}
+	if literals == nil {
+		return errors.New("literal table: no end-of-stream code")
+	}
+	distances, err := readTable(br, distanceAlphabetSize)
+	if err != nil {
+		return fmt.Errorf("distance table: %w", err)
+	}
+
+	// out holds the decoded bytes not yet written to dst, after up to window
+	// bytes already written that later matches may still copy from.
+	stream := chunks.NewBitReader(br)
+	limit := chunks.Remaining(dst)
+	out := make([]byte, 0, window+outputBufferSize+MaxMatch)
+	written, total := 0, int64(0)
+	for decoded := 0; ; decoded++ {
+		before := len(out)
+		done, err := decodeToken(stream, literals, distances, window, &out)
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return fmt.Errorf("token %d: %w", decoded, err)
+		}
+		if done {
+			break
+		}
+
+		if total += int64(len(out) - before); total > limit {
+			return fmt.Errorf("token %d: %w", decoded, chunks.ErrLimit)
+		}
+		if len(out)-written >= outputBufferSize {
+			if _, err := dst.Write(out[written:]); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+			kept := min(len(out), window)
+			out = out[:copy(out, out[len(out)-kept:])]
+			written = kept
+		}
+	}
+
+	if padding := stream.Buffered(); padding >= chunks.ChunkSize {
+		return fmt.Errorf("unexpected %d trailing bits after last symbol", padding)
+	}
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last symbol")
+	}
+
+	if _, err := dst.Write(out[written:]); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// ValidateWindowBits returns an error unless windowBits is between MinWindowBits
+// and MaxWindowBits.
+func ValidateWindowBits(windowBits int) error {
+	if windowBits < MinWindowBits || windowBits > MaxWindowBits {
+		return fmt.Errorf("window size of %d bits out of range %d..%d", windowBits, MinWindowBits, MaxWindowBits)
+	}
+	return nil
+}
+
+// decodeToken reads one token from stream and appends the bytes it stands for to
+// out. It reports whether the token was the end-of-stream symbol. distances is
+// nil if the stream has no matches.
+func decodeToken(stream *chunks.BitReader, literals, distances *decodingTree.LookupTable, window int, out *[]byte) (bool, error) {
+	symbol, err := literals.ReadSymbol(stream)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case symbol < endOfStream:
+		*out = append(*out, byte(symbol))
+		return false, nil
+	case symbol == endOfStream:
+		return true, nil
+	}
+
+	length, err := readBucket(stream, int(symbol-endOfStream-1))
+	if err != nil {
+		return false, err
+	}
+	length += MinMatch
+	if length > MaxMatch {
+		return false, fmt.Errorf("match length %d exceeds %d", length, MaxMatch)
+	}
+
+	if distances == nil {
+		return false, errors.New("match in a stream without distance codes")
+	}
+	code, err := distances.ReadSymbol(stream)
+	if err != nil {
+		return false, err
+	}
+	distance, err := readBucket(stream, int(code))
+	if err != nil {
+		return false, err
+	}
+	distance++
+	if distance > window || distance > len(*out) {
+		return false, fmt.Errorf("match distance %d beyond the window or the start of the data", distance)
+	}
+
+	// Byte by byte, since a match may overlap the bytes it produces.
+	start := len(*out) - distance
+	for i := range length {
+		*out = append(*out, (*out)[start+i])
+	}
+	return false, nil
+}
+
+// appendTable computes the Huffman code lengths for freqs, appends them to buf
+// and returns the canonical codes with the extended buffer.
+func appendTable(buf []byte, freqs table.Frequencies) (table.CodeTable, []byte, error) {
+	lengths := huffman.LengthsFromFrequencies(freqs)
+	codes, err := lengths.CanonicalCodes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build canonical table: %w", err)
+	}
+	serialised, err := table.MarshalLengths(lengths, len(freqs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal code lengths: %w", err)
+	}
+	return codes, append(buf, serialised...), nil
+}
+
+// readTable reads the code lengths of an alphabet of alphabetSize symbols from br
+// and builds a lookup table for them. It returns nil if no symbol has a code.
+func readTable(br *bufio.Reader, alphabetSize int) (*decodingTree.LookupTable, error) {
+	serialised := make([]byte, (alphabetSize+1)/2)
+	if _, err := io.ReadFull(br, serialised); err != nil {
+		return nil, fmt.Errorf("read code lengths: %w", err)
+	}
+	lengths, _, err := table.UnmarshalLengths(serialised, alphabetSize)
+	if err != nil {
+		return nil, fmt.Errorf("read code lengths: %w", err)
+	}
+	if len(lengths) == 0 {
+		return nil, nil
+	}
+	tree, err := decodingTree.BuildFromLengths(lengths)
+	if err != nil {
+		return nil, fmt.Errorf("build decoding tree: %w", err)
+	}
+	lookup, err := decodingTree.NewLookupTable(tree, decodingTree.DefaultLookupBits)
+	if err != nil {
+		return nil, fmt.Errorf("build decoding table: %w", err)
+	}
+	return lookup, nil
+}
+
+// bucket splits v into a bucket code and extra bits, as DEFLATE does for lengths
+// and distances: values below 4 have their own codes, and every further power of
+// two is split into two codes followed by the remaining low bits of v.
+func bucket(v uint32) (code int, extra uint, value uint32) {
+	if v < 4 {
+		return int(v), 0, 0
+	}
+	n := bits.Len32(v)
+	extra = uint(n - 2)
+	return 2*(n-1) + int(v>>extra&1), extra, v & (1<<extra - 1)
+}
+
+// readBucket reads the extra bits following the bucket code from stream and
+// returns the value, the inverse of bucket.
+func readBucket(stream *chunks.BitReader, code int) (int, error) {
+	if code < 4 {
+		return code, nil
+	}
+	extra := uint(code/2 - 1)
+	value, err := stream.ReadBits(extra)
+	if err != nil {
+		return 0, err
+	}
+	return (2|code&1)<<extra | int(value), nil
+}
@@ -0,0 +1,212 @@
+package lz77
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/huffman"
+)
+
+// logLines returns repetitive log-like text, the kind of input the dictionary
+// stage is for.
+func logLines(n int) []byte {
+	var sb strings.Builder
+	for i := range n {
+		fmt.Fprintf(&sb, "2024-01-02 10:%02d:%02d INFO server: handled request id=%d status=200\n", i/60%60, i%60, i*7)
+	}
+	return []byte(sb.String())
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 100000)
+	rng.Read(random)
+	farRepeat := append(bytes.Clone(random[:50000]), random[:50000]...)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		windowBits int
+	}{
+		{name: "empty", data: []byte{}, windowBits: DefaultWindowBits},
+		{name: "single byte", data: []byte("x"), windowBits: DefaultWindowBits},
+		{name: "overlapping run", data: bytes.Repeat([]byte("a"), 10000), windowBits: DefaultWindowBits},
+		{name: "text", data: []byte("abracadabra abracadabra, said the wizard\n"), windowBits: DefaultWindowBits},
+		{name: "logs", data: logLines(2000), windowBits: DefaultWindowBits},
+		{name: "random", data: random, windowBits: DefaultWindowBits},
+		{name: "smallest window", data: farRepeat, windowBits: MinWindowBits},
+		{name: "largest window", data: farRepeat, windowBits: MaxWindowBits},
+		{name: "longer than window", data: bytes.Repeat(random, 4), windowBits: MaxWindowBits},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := (Encoder{WindowBits: tt.windowBits}).Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestTokenizeRespectsWindow(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	data := make([]byte, 5000)
+	rng.Read(data)
+	data = append(data, data...)
+
+	for _, windowBits := range []int{MinWindowBits, 12, DefaultWindowBits} {
+		window := 1 << windowBits
+		var out []byte
+		matched := 0
+		for _, tok := range tokenize(data, window) {
+			if tok.length == 0 {
+				out = append(out, tok.literal)
+				continue
+			}
+			if tok.length < MinMatch || tok.length > MaxMatch {
+				t.Fatalf("window %d: match length %d out of range", window, tok.length)
+			}
+			if int(tok.distance) > window || int(tok.distance) > len(out) {
+				t.Fatalf("window %d: match distance %d at %d", window, tok.distance, len(out))
+			}
+			for range tok.length {
+				out = append(out, out[len(out)-int(tok.distance)])
+			}
+			matched += int(tok.length)
+		}
+		if !bytes.Equal(out, data) {
+			t.Fatalf("window %d: tokens do not reproduce the input", window)
+		}
+
+		// Only a window covering the 5000-byte distance finds the repeat.
+		if found := matched > len(data)/4; found != (window >= 5000) {
+			t.Errorf("window %d: matched %d of %d bytes", window, matched, len(data))
+		}
+	}
+}
+
+func TestEncodeBeatsHuffmanOnLogs(t *testing.T) {
+	data := logLines(2000)
+
+	var lz, prefix bytes.Buffer
+	if err := Encode(&lz, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if err := huffman.Encode(&prefix, bytes.NewReader(data)); err != nil {
+		t.Fatalf("huffman.Encode() failed: %v", err)
+	}
+	if lz.Len() >= prefix.Len()/3 {
+		t.Errorf("lz77 wrote %d bytes, want less than a third of Huffman's %d", lz.Len(), prefix.Len())
+	}
+}
+
+func TestEncodeRejectsInvalidWindow(t *testing.T) {
+	for _, windowBits := range []int{0, MinWindowBits - 1, MaxWindowBits + 1} {
+		if err := (Encoder{WindowBits: windowBits}).Encode(io.Discard, strings.NewReader("data")); err == nil {
+			t.Errorf("Encode() with a window of %d bits succeeded, want error", windowBits)
+		}
+	}
+}
+
+func TestDecodeRejectsDamagedStreams(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(logLines(50))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	stream := encoded.Bytes()
+
+	err := Decode(io.Discard, bytes.NewReader(stream[:len(stream)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode() of a truncated stream: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Decode(io.Discard, bytes.NewReader(append(bytes.Clone(stream), 0))); err == nil {
+		t.Error("Decode() with trailing data succeeded, want error")
+	}
+
+	badWindow := bytes.Clone(stream)
+	badWindow[0] = MaxWindowBits + 1
+	if err := Decode(io.Discard, bytes.NewReader(badWindow)); err == nil {
+		t.Error("Decode() with an invalid window size succeeded, want error")
+	}
+}
+
+func TestDecodeStopsAtLimit(t *testing.T) {
+	// Zeros encode as one literal followed by matches of MaxMatch bytes.
+	zeros := make([]byte, 1<<20)
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(zeros)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr error
+	}{
+		{name: "limit at length", limit: int64(len(zeros))},
+		{name: "limit below length", limit: 1000, wantErr: chunks.ErrLimit},
+		{name: "limit inside first match", limit: 2, wantErr: chunks.ErrLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded bytes.Buffer
+			err := Decode(chunks.LimitWriter(&decoded, tt.limit), bytes.NewReader(encoded.Bytes()))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if int64(decoded.Len()) > tt.limit {
+				t.Errorf("Decode() wrote %d bytes, want at most %d", decoded.Len(), tt.limit)
+			}
+			if err == nil && !bytes.Equal(decoded.Bytes(), zeros) {
+				t.Errorf("Decode() returned %d bytes, want %d zeros", decoded.Len(), len(zeros))
+			}
+		})
+	}
+}
+
+func TestBucketRoundTrip(t *testing.T) {
+	var packed bytes.Buffer
+	bw := chunks.NewBitWriter(&packed)
+	codes := make([]int, 0, 1<<MaxWindowBits)
+	for v := uint32(0); v < 1<<MaxWindowBits; v++ {
+		code, extra, value := bucket(v)
+		if code >= distanceAlphabetSize {
+			t.Fatalf("bucket(%d) = code %d, want below %d", v, code, distanceAlphabetSize)
+		}
+		if v <= MaxMatch-MinMatch && code >= lengthCodes {
+			t.Fatalf("bucket(%d) = code %d, want below %d for a length", v, code, lengthCodes)
+		}
+		if err := bw.WriteBits(uint64(value), extra); err != nil {
+			t.Fatalf("WriteBits() failed: %v", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	stream := chunks.NewBitReader(&packed)
+	for v, code := range codes {
+		got, err := readBucket(stream, code)
+		if err != nil {
+			t.Fatalf("readBucket() of %d failed: %v", v, err)
+		}
+		if got != v {
+			t.Fatalf("readBucket() = %d, want %d", got, v)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package lz77
+
+const (
+	// hashBits is the size in bits of the hash table heading the chains.
+	hashBits = 15
+
+	// maxChain bounds the number of earlier positions examined per match search,
+	// trading ratio for speed on highly repetitive data.
+	maxChain = 128
+
+	// goodMatch is the length from which a match is taken without checking whether
+	// the next position starts a longer one.
+	goodMatch = 32
+
+	// tooFar is the distance beyond which a match of only MinMatch bytes is dropped,
+	// since its extra distance bits cost more than the literals it replaces.
+	tooFar = 4096
+
+	// noPosition marks an empty hash bucket or the end of a chain.
+	noPosition = -1
+)
+
+// token is a literal byte or a back-reference copying length bytes from distance
+// bytes before the current position. Literals have a length of zero.
+type token struct {
+	literal  byte
+	length   uint16
+	distance uint32
+}
+
+// matchFinder finds earlier occurrences of the data at a position within a
+// sliding window. Positions are chained by the hash of their first MinMatch bytes:
+// head holds the latest position of every hash and prev, indexed modulo the
+// window size, the one before it with the same hash.
+type matchFinder struct {
+	data   []byte
+	window int
+	head   []int32
+	prev   []int32
+}
+
+// newMatchFinder returns a match finder over data with a window of window bytes,
+// which must be a power of two.
+func newMatchFinder(data []byte, window int) *matchFinder {
+	mf := &matchFinder{
+		data:   data,
+		window: window,
+		head:   make([]int32, 1<<hashBits),
+		prev:   make([]int32, window),
+	}
+	for i := range mf.head {
+		mf.head[i] = noPosition
+	}
+	return mf
+}
+
+// hash returns the hash of the MinMatch bytes at pos.
+func (mf *matchFinder) hash(pos int) uint32 {
+	v := uint32(mf.data[pos])<<16 | uint32(mf.data[pos+1])<<8 | uint32(mf.data[pos+2])
+	return v * 2654435761 >> (32 - hashBits)
+}
+
+// insert adds pos to the chain of its hash. Positions too close to the end of the
+// data to start a match are ignored.
+func (mf *matchFinder) insert(pos int) {
+	if pos+MinMatch > len(mf.data) {
+		return
+	}
+	h := mf.hash(pos)
+	mf.prev[pos&(mf.window-1)] = mf.head[h]
+	mf.head[h] = int32(pos)
+}
+
+// longest returns the length and distance of the longest match for the data at
+// pos among the earlier positions in its chain, or a length of zero if there is
+// none of at least MinMatch bytes. pos itself must not have been inserted yet.
+func (mf *matchFinder) longest(pos int) (length, distance int) {
+	if pos+MinMatch > len(mf.data) {
+		return 0, 0
+	}
+	limit := min(MaxMatch, len(mf.data)-pos)
+	target := mf.data[pos : pos+limit]
+
+	candidate := int(mf.head[mf.hash(pos)])
+	for chain := 0; chain < maxChain && candidate != noPosition && pos-candidate <= mf.window; chain++ {
+		// A longer match must also agree at the byte just past the best so far.
+		if length == 0 || mf.data[candidate+length] == target[length] {
+			n := 0
+			for n < limit && mf.data[candidate+n] == target[n] {
+				n++
+			}
+			if n > length {
+				length, distance = n, pos-candidate
+				if n == limit {
+					break
+				}
+			}
+		}
+
+		next := int(mf.prev[candidate&(mf.window-1)])
+		if next >= candidate {
+			break // the slot was reused by a position outside the window
+		}
+		candidate = next
+	}
+
+	if length < MinMatch || length == MinMatch && distance > tooFar {
+		return 0, 0
+	}
+	return length, distance
+}
+
+// tokenize splits data into literals and back-references within a window of
+// window bytes. It looks one position ahead before taking a short match, emitting
+// a literal instead when the next position starts a longer one.
+func tokenize(data []byte, window int) []token {
+	mf := newMatchFinder(data, window)
+	tokens := make([]token, 0, len(data)/4)
+
+	for pos := 0; pos < len(data); {
+		length, distance := mf.longest(pos)
+		mf.insert(pos)
+
+		if length >= MinMatch && length < goodMatch {
+			if next, _ := mf.longest(pos + 1); next > length {
+				tokens = append(tokens, token{literal: data[pos]})
+				pos++
+				continue
+			}
+		}
+
+		if length < MinMatch {
+			tokens = append(tokens, token{literal: data[pos]})
+			pos++
+			continue
+		}
+		tokens = append(tokens, token{length: uint16(length), distance: uint32(distance)})
+		for i := pos + 1; i < pos+length; i++ {
+			mf.insert(i)
+		}
+		pos += length
+	}
+	return tokens
+}
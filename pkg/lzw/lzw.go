@@ -0,0 +1,262 @@
+// Package lzw implements the Lempel-Ziv-Welch dictionary codec. Encoder and
+// decoder start from a dictionary holding every single byte and add one entry,
+// a known string extended by the byte that follows it, for every code sent, so
+// the dictionary itself is never stored.
+//
+// Codes start MinWidth bits wide and grow by a bit whenever the next code to be
+// assigned no longer fits, up to MaxWidth bits. When every MaxWidth-bit code is
+// taken, the encoder sends clearCode and both sides start again from the initial
+// dictionary. The stream ends with endCode, padded with zero bits.
+package lzw
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"slices"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+const (
+	// MinWidth is the width in bits of the first codes: enough for every byte
+	// value and the two control codes.
+	MinWidth = 9
+
+	// MaxWidth is the widest code. A full dictionary holds 1<<MaxWidth entries.
+	MaxWidth = 12
+
+	// clearCode tells the decoder to reset the dictionary.
+	clearCode = 256
+
+	// endCode closes every stream.
+	endCode = 257
+
+	// firstCode is the first code assigned to a dictionary entry.
+	firstCode = 258
+
+	// maxCodes is the number of codes of MaxWidth bits.
+	maxCodes = 1 << MaxWidth
+)
+
+// Encode reads src as it arrives, replaces the longest known string at every
+// step by its code and writes the codes, followed by endCode, to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	in := bufio.NewReader(src)
+	out := bufio.NewWriter(dst)
+	bw := chunks.NewBitWriter(out)
+
+	// dict maps a known string, as its code and the byte extending it, to the code
+	// of the extended string.
+	dict := make(map[uint32]uint16)
+	next := firstCode
+	prefix := -1
+
+	for {
+		b, err := in.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+
+		if prefix < 0 {
+			prefix = int(b)
+			continue
+		}
+		key := uint32(prefix)<<8 | uint32(b)
+		if code, ok := dict[key]; ok {
+			prefix = int(code)
+			continue
+		}
+
+		if err := bw.WriteBits(uint64(prefix), width(next-1)); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		if next < maxCodes {
+			dict[key] = uint16(next)
+			next++
+		} else {
+			if err := bw.WriteBits(clearCode, MaxWidth); err != nil {
+				return fmt.Errorf("encode: %w", err)
+			}
+			clear(dict)
+			next = firstCode
+		}
+		prefix = int(b)
+	}
+
+	if prefix >= 0 {
+		if err := bw.WriteBits(uint64(prefix), width(next-1)); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		// The decoder adds an entry for this code too, which may widen endCode.
+		next = min(next+1, maxCodes)
+	}
+	if err := bw.WriteBits(endCode, width(next-1)); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, rebuilding the dictionary as it goes,
+// and writes the decoded bytes to dst until endCode. Only the zero bits padding
+// the final byte may follow it. For a chunks.LimitedWriter, decoding stops with
+// chunks.ErrLimit at the first code past the limit.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the data is truncated or corrupt, or if writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+	stream := chunks.NewBitReader(br)
+	out := bufio.NewWriter(dst)
+	d := newDictionary()
+	limit := chunks.Remaining(dst)
+
+	for decoded := 0; ; decoded++ {
+		code, err := stream.ReadBits(d.width())
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return fmt.Errorf("code %d: %w", decoded, err)
+		}
+		if code == endCode {
+			break
+		}
+		if code == clearCode {
+			d.reset()
+			continue
+		}
+
+		s, err := d.decode(int(code))
+		if err != nil {
+			return fmt.Errorf("code %d: %w", decoded, err)
+		}
+		if limit -= int64(len(s)); limit < 0 {
+			return fmt.Errorf("code %d: %w", decoded, chunks.ErrLimit)
+		}
+		if _, err := out.Write(s); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+	}
+
+	if padding := stream.Buffered(); padding >= chunks.ChunkSize {
+		return fmt.Errorf("unexpected %d trailing bits after last code", padding)
+	}
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last code")
+	}
+
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// width returns the number of bits needed to send codes up to highest.
+func width(highest int) uint {
+	return uint(max(MinWidth, bits.Len(uint(highest))))
+}
+
+// dictionary is the decoder's copy of the dictionary. Every entry is a shorter
+// entry extended by one byte, stored as the code of that entry and the byte.
+type dictionary struct {
+	prefix [maxCodes]uint16
+	suffix [maxCodes]byte
+	next   int
+	prev   int    // the previous code, or -1 right after a reset
+	buf    []byte // scratch space for expand
+}
+
+// newDictionary returns the initial dictionary of single bytes.
+func newDictionary() *dictionary {
+	d := &dictionary{}
+	for b := range 256 {
+		d.suffix[b] = byte(b)
+	}
+	d.reset()
+	return d
+}
+
+// reset forgets every entry added since the start.
+func (d *dictionary) reset() {
+	d.next = firstCode
+	d.prev = -1
+}
+
+// width returns the width of the next code. The encoder adds the entry for a
+// code as soon as it sends it, but the decoder only once it sees the following
+// code, so it is one entry behind except right after a reset.
+func (d *dictionary) width() uint {
+	next := d.next
+	if d.prev >= 0 && next < maxCodes {
+		next++
+	}
+	return width(next - 1)
+}
+
+// decode returns the string of code, adding the entry the encoder added after
+// the previous code. The string is only valid until the next call.
+func (d *dictionary) decode(code int) ([]byte, error) {
+	if d.prev < 0 {
+		if code > 255 {
+			return nil, fmt.Errorf("invalid first code %d", code)
+		}
+		d.prev = code
+		return d.expand(code), nil
+	}
+
+	var s []byte
+	switch {
+	case code < 256 || code >= firstCode && code < d.next:
+		s = d.expand(code)
+	case code == d.next && d.next < maxCodes:
+		// The string being defined by this very code: the previous string
+		// extended by its own first byte.
+		s = d.expand(d.prev)
+		s = append(s, s[0])
+	default:
+		return nil, fmt.Errorf("invalid code %d with %d entries", code, d.next)
+	}
+
+	if d.next < maxCodes {
+		d.prefix[d.next] = uint16(d.prev)
+		d.suffix[d.next] = s[0]
+		d.next++
+	}
+	d.prev = code
+	return s, nil
+}
+
+// expand returns the string of code, read backwards through the prefixes into buf.
+func (d *dictionary) expand(code int) []byte {
+	d.buf = d.buf[:0]
+	for code >= firstCode {
+		d.buf = append(d.buf, d.suffix[code])
+		code = int(d.prefix[code])
+	}
+	d.buf = append(d.buf, d.suffix[code])
+	slices.Reverse(d.buf)
+	return d.buf
+}
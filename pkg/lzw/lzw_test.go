@@ -0,0 +1,177 @@
+package lzw
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 100000)
+	rng.Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "single byte", data: []byte("x")},
+		{name: "code defined by itself", data: bytes.Repeat([]byte("a"), 10000)},
+		{name: "text", data: []byte("TOBEORNOTTOBEORTOBEORNOT#")},
+		{name: "every byte", data: append(all, all...)},
+		{name: "repeated lines", data: []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 2000))},
+		{name: "random", data: random},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestCodeWidthsAndReset(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	random := make([]byte, 20000)
+	rng.Read(random)
+	letters := make([]byte, 2000)
+	for i := range letters {
+		letters[i] = byte('a' + rng.Intn(4))
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		widest     uint
+		wantResets bool
+	}{
+		{name: "short text", data: []byte("TOBEORNOTTOBEORTOBEORNOT#"), widest: MinWidth},
+		{name: "past 512 codes", data: letters, widest: MinWidth + 1},
+		{name: "random", data: random, widest: MaxWidth, wantResets: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			// Walk the codes as Decode does, noting their widths and the resets.
+			stream := chunks.NewBitReader(&encoded)
+			d := newDictionary()
+			var widest uint
+			resets := 0
+			for {
+				w := d.width()
+				widest = max(widest, w)
+				code, err := stream.ReadBits(w)
+				if err != nil {
+					t.Fatalf("ReadBits() failed: %v", err)
+				}
+				if code == endCode {
+					break
+				}
+				if code == clearCode {
+					resets++
+					d.reset()
+					continue
+				}
+				if _, err := d.decode(int(code)); err != nil {
+					t.Fatalf("decode() failed: %v", err)
+				}
+			}
+
+			if widest != tt.widest {
+				t.Errorf("widest code = %d bits, want %d", widest, tt.widest)
+			}
+			if (resets > 0) != tt.wantResets {
+				t.Errorf("dictionary reset %d times, want resets: %v", resets, tt.wantResets)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsDamagedStreams(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, strings.NewReader(strings.Repeat("truncate me, please. ", 20))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	stream := encoded.Bytes()
+
+	err := Decode(io.Discard, bytes.NewReader(stream[:len(stream)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode() of a truncated stream: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Decode(io.Discard, bytes.NewReader(append(bytes.Clone(stream), 0))); err == nil {
+		t.Error("Decode() with trailing data succeeded, want error")
+	}
+
+	// An entry code before any entry has been defined.
+	var bad bytes.Buffer
+	bw := chunks.NewBitWriter(&bad)
+	for _, code := range []uint64{'a', firstCode + 5, endCode} {
+		if err := bw.WriteBits(code, MinWidth); err != nil {
+			t.Fatalf("WriteBits() failed: %v", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	if err := Decode(io.Discard, &bad); err == nil {
+		t.Error("Decode() with an undefined code succeeded, want error")
+	}
+}
+
+func TestDecodeStopsAtLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 100000)
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr error
+	}{
+		{name: "limit at length", limit: int64(len(data))},
+		{name: "limit below length", limit: int64(len(data)) - 1, wantErr: chunks.ErrLimit},
+		{name: "limit inside first code", limit: 0, wantErr: chunks.ErrLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded bytes.Buffer
+			err := Decode(chunks.LimitWriter(&decoded, tt.limit), bytes.NewReader(encoded.Bytes()))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if int64(decoded.Len()) > tt.limit {
+				t.Errorf("Decode() wrote %d bytes, want at most %d", decoded.Len(), tt.limit)
+			}
+			if err == nil && !bytes.Equal(decoded.Bytes(), data) {
+				t.Errorf("Decode() returned %d bytes, want %d matching bytes", decoded.Len(), len(data))
+			}
+		})
+	}
+}
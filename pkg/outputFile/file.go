@@ -0,0 +1,163 @@
+package outputFile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/fileMetadata"
+)
+
+// File is an output file being written. Data goes to a temporary file next to the
+// final path; Commit moves it into place and Abort discards it. Exactly one of
+// them must be called, and Abort may follow Commit as a deferred no-op.
+type File struct {
+	temp   *os.File
+	path   string
+	policy Policy
+	done   bool
+}
+
+// Create starts writing the output file meant for path, following p if a file
+// already exists there; see Path. The file gets the permissions perm, less the
+// umask, when it is committed.
+//
+// Parameters:
+//   - path: The intended output path.
+//   - perm: The permissions of the new file.
+//   - p: The policy for an existing file and for failed output.
+//
+// Returns:
+//   - *File: The output file, open for writing.
+//   - error: An error from Path, or if the temporary file cannot be created.
+func Create(path string, perm fs.FileMode, p Policy) (*File, error) {
+	target, err := Path(path, p)
+	if err != nil {
+		return nil, err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	if err := temp.Chmod(perm &^ fileMetadata.Umask()); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return nil, err
+	}
+
+	f := &File{temp: temp, path: target, policy: p}
+	track(f)
+	return f, nil
+}
+
+// Write writes p to the temporary file.
+func (f *File) Write(p []byte) (int, error) {
+	return f.temp.Write(p)
+}
+
+// Name returns the path the file is committed to.
+func (f *File) Name() string {
+	return f.path
+}
+
+// Stat describes the temporary file being written, for example to recognise it
+// while walking its directory.
+func (f *File) Stat() (fs.FileInfo, error) {
+	return f.temp.Stat()
+}
+
+// Commit flushes the data to disk and renames the temporary file to Name. Unless
+// the policy has Force set, an existing file at Name is not replaced, even one
+// that appeared after Create, and ErrExists is returned. On error the temporary
+// file is handled as by Abort.
+//
+// Returns:
+//   - error: An error if syncing, closing or renaming fails.
+func (f *File) Commit() error {
+	if f.done {
+		return errors.New("outputFile: commit of finished file")
+	}
+
+	err := f.temp.Sync()
+	if closeErr := f.temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = f.rename()
+	}
+	if err != nil {
+		f.discard()
+		return err
+	}
+
+	f.done = true
+	untrack(f)
+	syncDir(filepath.Dir(f.path))
+	return nil
+}
+
+// rename moves the closed temporary file to its final path.
+func (f *File) rename() error {
+	if f.policy.Force {
+		return os.Rename(f.temp.Name(), f.path)
+	}
+
+	// A hard link fails rather than replace a file that appeared meanwhile.
+	err := os.Link(f.temp.Name(), f.path)
+	switch {
+	case err == nil:
+		return os.Remove(f.temp.Name())
+	case errors.Is(err, fs.ErrExist):
+		return fmt.Errorf("%s: %w", f.path, ErrExists)
+	}
+
+	// Some file systems cannot link; check and rename instead.
+	if _, err := os.Lstat(f.path); err == nil {
+		return fmt.Errorf("%s: %w", f.path, ErrExists)
+	}
+	return os.Rename(f.temp.Name(), f.path)
+}
+
+// Abort discards the output: the temporary file is closed and removed, or kept
+// and reported if the policy has KeepPartial set. Abort does nothing after Commit
+// or an earlier Abort.
+func (f *File) Abort() {
+	if f.done {
+		return
+	}
+	_ = f.temp.Close()
+	f.discard()
+}
+
+// discard removes or keeps the closed temporary file and finishes f.
+func (f *File) discard() {
+	f.done = true
+	untrack(f)
+	removeTemp(f)
+}
+
+// removeTemp removes the temporary file of f, or reports it under KeepPartial.
+func removeTemp(f *File) {
+	if f.policy.KeepPartial {
+		log.Printf("Warning: partial output for %s kept in %s", f.path, f.temp.Name())
+		return
+	}
+	if err := os.Remove(f.temp.Name()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Warning: failed to remove %s: %v", f.temp.Name(), err)
+	}
+}
+
+// syncDir flushes the directory entry of a renamed file to disk. It is best
+// effort: some platforms cannot sync directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,117 @@
+// Package outputFile creates the files written by the pack and unpack commands.
+// An existing file is never overwritten unless the Policy chosen on the command
+// line allows it: by default the command fails, and --force, --no-clobber and
+// --suffix overwrite the file, skip it or pick a free name next to it instead.
+//
+// Output is written to a temporary file in the destination directory, which is
+// only synced and renamed into place once complete, so a crash, a full disk or an
+// interrupt never leaves a truncated file under the final name.
+package outputFile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ErrExists is returned for an existing output file when the Policy allows
+	// neither overwriting it nor writing elsewhere.
+	ErrExists = errors.New("file already exists (use --force to overwrite)")
+
+	// ErrSkipped is returned for an existing output file under Policy.NoClobber.
+	// Callers report it and carry on.
+	ErrSkipped = errors.New("file already exists, skipped")
+)
+
+// Policy says what happens when an output file already exists, and to the
+// temporary file of an output that fails. The zero Policy refuses to touch an
+// existing file and removes failed output. At most one of Force, NoClobber and
+// Suffix should be set.
+type Policy struct {
+	// Force overwrites the existing file.
+	Force bool
+
+	// NoClobber leaves the existing file alone and skips the output.
+	NoClobber bool
+
+	// Suffix writes to the first free name made by Suffixed instead.
+	Suffix bool
+
+	// KeepPartial leaves the temporary file of a failed or interrupted output in
+	// place for debugging instead of removing it.
+	KeepPartial bool
+}
+
+// AddFlags registers the --force, --no-clobber, --suffix and --keep-partial flags
+// of cmd, storing them in p.
+//
+// Parameters:
+//   - cmd: The command accepting the flags.
+func (p *Policy) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&p.Force, "force", "f", false, "overwrite existing output files")
+	cmd.Flags().BoolVarP(&p.NoClobber, "no-clobber", "n", false, "skip output files that already exist")
+	cmd.Flags().BoolVar(&p.Suffix, "suffix", false, "write to name.1.ext, name.2.ext, ... instead of an existing output file")
+	cmd.MarkFlagsMutuallyExclusive("force", "no-clobber", "suffix")
+	cmd.Flags().BoolVar(&p.KeepPartial, "keep-partial", false, "keep the temporary file of failed output (debugging)")
+}
+
+// Path returns the path to write the output meant for path to. It is path itself
+// if nothing exists there or p.Force is set, in which case the caller replaces
+// the existing file, or with p.Suffix the first of Suffixed(path, 1),
+// Suffixed(path, 2), ... that does not exist.
+//
+// Parameters:
+//   - path: The intended output path.
+//   - p: The policy for an existing file.
+//
+// Returns:
+//   - string: The path to write to.
+//   - error: ErrExists or ErrSkipped, wrapped with path, if the file exists and p
+//     does not allow writing, or an error if checking for the file fails.
+func Path(path string, p Policy) (string, error) {
+	for n := 0; ; n++ {
+		candidate := path
+		if n > 0 {
+			candidate = Suffixed(path, n)
+		}
+
+		_, err := os.Lstat(candidate)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return candidate, nil
+		case err != nil:
+			return "", err
+		case p.Force:
+			return candidate, nil
+		case p.NoClobber:
+			return "", fmt.Errorf("%s: %w", path, ErrSkipped)
+		case !p.Suffix:
+			return "", fmt.Errorf("%s: %w", path, ErrExists)
+		}
+	}
+}
+
+// IsInput reports whether path names the input file described by input, which
+// writing the output there would destroy while it is being read.
+func IsInput(path string, input fs.FileInfo) bool {
+	info, err := os.Stat(path)
+	return err == nil && os.SameFile(info, input)
+}
+
+// Suffixed returns path with the number n inserted before its extension, so that
+// "notes.txt" becomes "notes.1.txt" and "README" or ".profile" becomes
+// "README.1" or ".profile.1".
+func Suffixed(path string, n int) string {
+	ext := filepath.Ext(path)
+	if ext == filepath.Base(path) {
+		ext = ""
+	}
+	return strings.TrimSuffix(path, ext) + "." + strconv.Itoa(n) + ext
+}
@@ -0,0 +1,166 @@
+package outputFile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathPolicies(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "notes.txt")
+	for _, name := range []string{"notes.txt", "notes.1.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fresh := filepath.Join(dir, "fresh.txt")
+
+	tests := []struct {
+		name    string
+		path    string
+		policy  Policy
+		want    string
+		wantErr error
+	}{
+		{"new file", fresh, Policy{}, fresh, nil},
+		{"refuse", existing, Policy{}, "", ErrExists},
+		{"force", existing, Policy{Force: true}, existing, nil},
+		{"no clobber", existing, Policy{NoClobber: true}, "", ErrSkipped},
+		{"suffix", existing, Policy{Suffix: true}, filepath.Join(dir, "notes.2.txt"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Path(tt.path, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Path() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateReplacesSymlinkWithForce(t *testing.T) {
+	dir := t.TempDir()
+	victim := filepath.Join(dir, "victim")
+	if err := os.WriteFile(victim, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "out.txt")
+	if err := os.Symlink(victim, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if _, err := Create(link, 0644, Policy{}); !errors.Is(err, ErrExists) {
+		t.Fatalf("Create() error = %v, want %v", err, ErrExists)
+	}
+	f, err := Create(link, 0644, Policy{Force: true})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if _, err := f.Write([]byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(victim); string(data) != "keep" {
+		t.Errorf("Create() wrote through the symlink: victim holds %q", data)
+	}
+	if info, err := os.Lstat(link); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("Create() left %s as %v, want a regular file", link, info.Mode())
+	}
+}
+
+func TestCommitAndAbort(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "out.vlc")
+
+	f, err := Create(target, 0644, Policy{})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if _, err := f.Write([]byte("complete")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(target); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("output visible before Commit(): %v", err)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	f.Abort()
+	if data, err := os.ReadFile(target); err != nil || string(data) != "complete" {
+		t.Errorf("committed file holds %q, %v, want %q", data, err, "complete")
+	}
+
+	// A file appearing while the output is written is not replaced.
+	other := filepath.Join(dir, "other.vlc")
+	f, err = Create(other, 0644, Policy{})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := os.WriteFile(other, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Commit(); !errors.Is(err, ErrExists) {
+		t.Errorf("Commit() over a new file: error = %v, want %v", err, ErrExists)
+	}
+	if data, _ := os.ReadFile(other); string(data) != "keep" {
+		t.Errorf("Commit() replaced a new file: it holds %q", data)
+	}
+
+	// Aborted output leaves only the committed files, unless KeepPartial is set.
+	f, err = Create(filepath.Join(dir, "failed.vlc"), 0644, Policy{})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	f.Abort()
+	if names := dirNames(t, dir); len(names) != 2 {
+		t.Errorf("directory holds %v after Abort(), want out.vlc and other.vlc", names)
+	}
+	f, err = Create(filepath.Join(dir, "kept.vlc"), 0644, Policy{KeepPartial: true})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	f.Abort()
+	if names := dirNames(t, dir); len(names) != 3 {
+		t.Errorf("directory holds %v after Abort() with KeepPartial, want the temporary file too", names)
+	}
+}
+
+// dirNames returns the names in dir.
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestSuffixed(t *testing.T) {
+	tests := []struct {
+		path string
+		n    int
+		want string
+	}{
+		{"notes.txt", 1, "notes.1.txt"},
+		{"dir/archive.vlc", 12, "dir/archive.12.vlc"},
+		{"README", 2, "README.2"},
+		{".profile", 1, ".profile.1"},
+	}
+	for _, tt := range tests {
+		if got := Suffixed(tt.path, tt.n); got != tt.want {
+			t.Errorf("Suffixed(%q, %d) = %q, want %q", tt.path, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package outputFile
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// pending tracks the output files not yet committed or aborted, so an interrupt
+// can clean up their temporary files.
+var pending = struct {
+	sync.Mutex
+	files map[*File]bool
+}{files: make(map[*File]bool)}
+
+// installHandler starts the signal handler the first time an output file is created.
+var installHandler sync.Once
+
+// track records f as pending.
+func track(f *File) {
+	installHandler.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		go handleSignal(signals)
+	})
+
+	pending.Lock()
+	defer pending.Unlock()
+	pending.files[f] = true
+}
+
+// untrack forgets f once it is committed or aborted.
+func untrack(f *File) {
+	pending.Lock()
+	defer pending.Unlock()
+	delete(pending.files, f)
+}
+
+// handleSignal waits for a termination signal, removes the temporary files of
+// every pending output and then lets the signal terminate the process as usual.
+func handleSignal(signals chan os.Signal) {
+	sig := <-signals
+
+	// Keep the lock so no output is committed or created while exiting.
+	pending.Lock()
+	for f := range pending.files {
+		_ = f.temp.Close()
+		removeTemp(f)
+	}
+
+	signal.Reset()
+	if p, err := os.FindProcess(os.Getpid()); err == nil {
+		_ = p.Signal(sig)
+	}
+	os.Exit(1)
+}
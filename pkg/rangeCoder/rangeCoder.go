@@ -0,0 +1,289 @@
+// Package rangeCoder implements a range coder, a form of arithmetic coding, driven
+// by an adaptive order-0 model of byte frequencies. Unlike a prefix code, it can
+// spend a fraction of a bit on a very frequent symbol, which brings highly skewed
+// data close to its entropy. Encoder and decoder update the same model after every
+// symbol, so no table is stored and data is encoded in a single pass.
+//
+// The coder is the byte-oriented carry-propagating design also used by LZMA: a
+// 32-bit range, renormalised a byte at a time. The stream ends with the
+// pseudo-symbol endOfStream, followed by the bytes flushing the coder state.
+package rangeCoder
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+const (
+	// endOfStream is the pseudo-symbol closing every stream, after the 256 byte values.
+	endOfStream = 256
+
+	// alphabetSize is the number of symbols in the model.
+	alphabetSize = endOfStream + 1
+
+	// topValue is the lower bound of the range after renormalisation.
+	topValue = 1 << 24
+
+	// maxTotal bounds the sum of the model frequencies, keeping at least 8 bits of
+	// precision in range / total.
+	maxTotal = 1 << 16
+
+	// increment is added to the frequency of every coded symbol. Larger steps adapt
+	// faster; the model is rescaled whenever the total passes maxTotal.
+	increment = 32
+
+	// flushBytes is the number of bytes the encoder writes to finish a stream, and
+	// the decoder reads to start one.
+	flushBytes = 5
+)
+
+// ErrCorrupt is returned when the encoded data does not decode to a valid symbol.
+var ErrCorrupt = errors.New("corrupt range coder data")
+
+// Encode reads src as it arrives, codes every byte with the adaptive model and
+// writes the encoded stream, ended by the end-of-stream symbol, to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	in := bufio.NewReader(src)
+	out := bufio.NewWriter(dst)
+	e := &encoder{out: out, rng: 0xFFFFFFFF, cacheSize: 1}
+	m := newModel()
+
+	for {
+		b, err := in.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		e.encode(m.cumulative(int(b)), m.freq[b], m.total)
+		m.update(int(b))
+	}
+	e.encode(m.cumulative(endOfStream), m.freq[endOfStream], m.total)
+
+	for i := 0; i < flushBytes; i++ {
+		e.shiftLow()
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, updating the model as it goes, and
+// writes the decoded bytes to dst until the end-of-stream symbol. Nothing may
+// follow the bytes that finish the stream. For a chunks.LimitedWriter, decoding
+// stops with chunks.ErrLimit at the first symbol past the limit.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the data is truncated or corrupt, or if writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	in := bufio.NewReader(src)
+	out := bufio.NewWriter(dst)
+	d := &decoder{in: in, rng: 0xFFFFFFFF}
+	for i := 0; i < flushBytes; i++ {
+		if err := d.shift(); err != nil {
+			return fmt.Errorf("read coder state: %w", err)
+		}
+	}
+	m := newModel()
+	limit := chunks.Remaining(dst)
+
+	for decoded := 0; ; decoded++ {
+		target, err := d.target(m.total)
+		if err != nil {
+			return fmt.Errorf("symbol %d: %w", decoded, err)
+		}
+		symbol := m.find(target)
+		if err := d.decode(m.cumulative(symbol), m.freq[symbol], m.total); err != nil {
+			return fmt.Errorf("symbol %d: %w", decoded, err)
+		}
+		if symbol == endOfStream {
+			break
+		}
+		if int64(decoded) >= limit {
+			return fmt.Errorf("symbol %d: %w", decoded, chunks.ErrLimit)
+		}
+		if err := out.WriteByte(byte(symbol)); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+		m.update(symbol)
+	}
+
+	if _, err := in.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last symbol")
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// encoder is the state of the range encoder. low holds the start of the range
+// with a carry bit above its low 32 bits; bytes that a carry may still change are
+// held back as cache followed by cacheSize-1 0xFF bytes.
+type encoder struct {
+	out       *bufio.Writer
+	low       uint64
+	rng       uint32
+	cache     byte
+	cacheSize int
+}
+
+// encode narrows the range to the symbol occupying [cum, cum+freq) of total.
+func (e *encoder) encode(cum, freq, total uint32) {
+	r := e.rng / total
+	e.low += uint64(r * cum)
+	e.rng = r * freq
+	for e.rng < topValue {
+		e.rng <<= 8
+		e.shiftLow()
+	}
+}
+
+// shiftLow moves the top byte of low out, writing the held-back bytes once no
+// carry can reach them any more.
+func (e *encoder) shiftLow() {
+	if uint32(e.low) < 0xFF000000 || e.low >= 1<<32 {
+		carry := byte(e.low >> 32)
+		for temp := e.cache; e.cacheSize > 0; e.cacheSize-- {
+			_ = e.out.WriteByte(temp + carry)
+			temp = 0xFF
+		}
+		e.cache = byte(e.low >> 24)
+	}
+	e.cacheSize++
+	e.low = (e.low & 0x00FFFFFF) << 8
+}
+
+// decoder is the state of the range decoder: the range and the offset of the
+// encoded value within it.
+type decoder struct {
+	in   *bufio.Reader
+	rng  uint32
+	code uint32
+	r    uint32 // rng / total of the symbol being decoded
+}
+
+// target returns the position of the next symbol within the model's total.
+func (d *decoder) target(total uint32) (uint32, error) {
+	d.r = d.rng / total
+	v := d.code / d.r
+	if v >= total {
+		return 0, ErrCorrupt
+	}
+	return v, nil
+}
+
+// decode removes the symbol occupying [cum, cum+freq) of total, as found by
+// target, from the range and renormalises it.
+func (d *decoder) decode(cum, freq, total uint32) error {
+	d.code -= d.r * cum
+	d.rng = d.r * freq
+	for d.rng < topValue {
+		d.rng <<= 8
+		if err := d.shift(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shift appends the next encoded byte to code.
+func (d *decoder) shift() error {
+	b, err := d.in.ReadByte()
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	d.code = d.code<<8 | uint32(b)
+	return nil
+}
+
+// model is the adaptive order-0 model: a frequency for every symbol, kept with
+// their cumulative sums in a Fenwick tree for logarithmic-time coding.
+type model struct {
+	freq  [alphabetSize]uint32
+	tree  [alphabetSize + 1]uint32 // Fenwick tree of freq, 1-based
+	total uint32
+}
+
+// newModel returns a model giving every symbol frequency 1.
+func newModel() *model {
+	m := &model{}
+	for s := range m.freq {
+		m.freq[s] = 1
+	}
+	m.rebuild()
+	return m
+}
+
+// update counts another occurrence of symbol, rescaling the model if the total
+// grows too large.
+func (m *model) update(symbol int) {
+	m.freq[symbol] += increment
+	m.total += increment
+	if m.total > maxTotal {
+		for s, f := range m.freq {
+			m.freq[s] = (f + 1) / 2
+		}
+		m.rebuild()
+		return
+	}
+	for i := symbol + 1; i < len(m.tree); i += i & -i {
+		m.tree[i] += increment
+	}
+}
+
+// rebuild recomputes the total and the Fenwick tree from freq.
+func (m *model) rebuild() {
+	m.total = 0
+	for i := 1; i < len(m.tree); i++ {
+		m.tree[i] = m.freq[i-1]
+		m.total += m.freq[i-1]
+	}
+	for i := 1; i < len(m.tree); i++ {
+		if parent := i + i&-i; parent < len(m.tree) {
+			m.tree[parent] += m.tree[i]
+		}
+	}
+}
+
+// cumulative returns the sum of the frequencies of the symbols before symbol.
+func (m *model) cumulative(symbol int) uint32 {
+	var sum uint32
+	for i := symbol; i > 0; i -= i & -i {
+		sum += m.tree[i]
+	}
+	return sum
+}
+
+// find returns the symbol whose interval [cumulative, cumulative+freq) contains
+// target, which must be below total.
+func (m *model) find(target uint32) int {
+	pos := 0
+	for step := 1 << 8; step > 0; step >>= 1 {
+		if next := pos + step; next < len(m.tree) && m.tree[next] <= target {
+			pos = next
+			target -= m.tree[next]
+		}
+	}
+	return pos
+}
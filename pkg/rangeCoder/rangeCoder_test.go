@@ -0,0 +1,154 @@
+package rangeCoder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 300000)
+	rng.Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "single symbol", data: bytes.Repeat([]byte("a"), 100000)},
+		{name: "text", data: []byte("abracadabra, said the wizard\n")},
+		{name: "every byte", data: append(all, all...)},
+		{name: "random", data: random},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestEncodeSpendsFractionalBits(t *testing.T) {
+	// 97% of the symbols are 'a': any prefix code needs at least one bit per symbol,
+	// while the entropy is about 0.2 bits.
+	rng := rand.New(rand.NewSource(3))
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = 'a'
+		if rng.Intn(100) < 3 {
+			data[i] = byte('b' + rng.Intn(3))
+		}
+	}
+
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if limit := len(data) / 8 / 2; encoded.Len() > limit {
+		t.Errorf("Encode() wrote %d bytes, want at most %d (half a bit per symbol)", encoded.Len(), limit)
+	}
+}
+
+func TestDecodeRejectsDamagedStreams(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader([]byte("truncate me, please"))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	stream := encoded.Bytes()
+
+	err := Decode(io.Discard, bytes.NewReader(stream[:len(stream)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode() of a truncated stream: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Decode(io.Discard, bytes.NewReader(append(bytes.Clone(stream), 0))); err == nil {
+		t.Error("Decode() with trailing data succeeded, want error")
+	}
+}
+
+func TestModelFind(t *testing.T) {
+	m := newModel()
+	for _, s := range []int{'x', 'x', 0, endOfStream, 'x'} {
+		m.update(s)
+	}
+	for s := 0; s < alphabetSize; s++ {
+		cum := m.cumulative(s)
+		for _, target := range []uint32{cum, cum + m.freq[s] - 1} {
+			if got := m.find(target); got != s {
+				t.Fatalf("find(%d) = %d, want %d", target, got, s)
+			}
+		}
+	}
+	if m.cumulative(alphabetSize) != m.total {
+		t.Errorf("cumulative(alphabetSize) = %d, want total %d", m.cumulative(alphabetSize), m.total)
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+	return n, err
+}
+
+func TestDecodeStopsAtLimit(t *testing.T) {
+	// Zeros cost a fraction of a bit each, so a short stream expands a lot.
+	data := make([]byte, 16<<20)
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		limit    int64
+		wantErr  error
+		wantRead int // most encoded bytes Decode may read, 0 for all of them
+	}{
+		{name: "limit at length", limit: int64(len(data))},
+		{name: "limit below length", limit: int64(len(data)) - 1, wantErr: chunks.ErrLimit},
+		{name: "limit of one byte", limit: 1, wantErr: chunks.ErrLimit, wantRead: encoded.Len() / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded bytes.Buffer
+			src := &countingReader{r: bytes.NewReader(encoded.Bytes())}
+			err := Decode(chunks.LimitWriter(&decoded, tt.limit), src)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if int64(decoded.Len()) > tt.limit {
+				t.Errorf("Decode() wrote %d bytes, want at most %d", decoded.Len(), tt.limit)
+			}
+			if tt.wantRead > 0 && src.n > tt.wantRead {
+				t.Errorf("Decode() read %d of %d encoded bytes before failing, want at most %d", src.n, encoded.Len(), tt.wantRead)
+			}
+			if err == nil && !bytes.Equal(decoded.Bytes(), data) {
+				t.Errorf("Decode() returned %d bytes, want %d matching bytes", decoded.Len(), len(data))
+			}
+		})
+	}
+}
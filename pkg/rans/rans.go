@@ -0,0 +1,196 @@
+// Package rans implements a static range asymmetric numeral system (rANS) codec
+// over bytes. It uses the same symbol model as the huffman package, byte
+// frequencies counted with table.CountBytes, but normalises them to a total of
+// 1<<ProbBits instead of deriving code lengths, so frequent symbols cost a
+// fraction of a bit as with arithmetic coding. Decoding needs one table lookup per
+// symbol, with no tree walk, which keeps it as fast as table-driven Huffman.
+//
+// An encoded stream is the normalized frequency table (see
+// table.MarshalFrequencies), the number of bytes as a uvarint, and then the coder
+// state as a 4-byte big-endian integer followed by the renormalisation bytes in
+// the order the decoder consumes them. An empty input has no table or state, just
+// a count of zero.
+package rans
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+const (
+	// ProbBits is the precision of the normalized frequencies: they sum to 1<<ProbBits.
+	ProbBits = 12
+
+	// probScale is the total of the normalized frequencies.
+	probScale = 1 << ProbBits
+
+	// lowerBound is the smallest coder state after renormalisation. The state is
+	// kept in [lowerBound, lowerBound<<8), so it always fits in 32 bits.
+	lowerBound = 1 << 23
+
+	// stateSize is the size in bytes of the final coder state written by the encoder.
+	stateSize = 4
+)
+
+// Encode reads src to the end, builds a normalized frequency table for it and
+// writes the table, the byte count and the rANS-coded data to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+	if len(data) == 0 {
+		_, err := dst.Write(binary.AppendUvarint(nil, 0))
+		return err
+	}
+
+	freqs, err := table.CountBytes(data).Normalize(ProbBits)
+	if err != nil {
+		return fmt.Errorf("normalize frequencies: %w", err)
+	}
+	header, err := table.MarshalFrequencies(freqs)
+	if err != nil {
+		return fmt.Errorf("marshal frequencies: %w", err)
+	}
+	header = binary.AppendUvarint(header, uint64(len(data)))
+
+	var starts [table.ByteAlphabetSize]uint32
+	for symbol := 1; symbol < table.ByteAlphabetSize; symbol++ {
+		starts[symbol] = starts[symbol-1] + freqs[symbol-1]
+	}
+
+	// rANS works as a stack: the data is encoded last byte first and the output,
+	// collected back to front, is reversed so the decoder reads it forwards.
+	packed := make([]byte, 0, len(data)/2+stateSize)
+	state := uint32(lowerBound)
+	for _, b := range slices.Backward(data) {
+		freq := freqs[b]
+		for limit := uint32((lowerBound>>ProbBits)<<8) * freq; state >= limit; state >>= 8 {
+			packed = append(packed, byte(state))
+		}
+		state = (state/freq)<<ProbBits + state%freq + starts[b]
+	}
+	packed = binary.LittleEndian.AppendUint32(packed, state)
+	slices.Reverse(packed)
+
+	if _, err := dst.Write(header); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	if _, err := dst.Write(packed); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, rebuilds the decoding table from the
+// frequencies at its start and writes exactly the recorded number of bytes to dst.
+// The coder must end in its initial state with every byte consumed.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the table is invalid, the data is truncated or corrupt,
+//     or writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+
+	// The count comes first only for an empty input, which has no table.
+	if first, err := br.Peek(1); err != nil {
+		return fmt.Errorf("read frequencies: %w", err)
+	} else if first[0] == 0 {
+		_, _ = br.ReadByte()
+		return expectEnd(br)
+	}
+
+	freqs, err := table.UnmarshalFrequencies(br, ProbBits)
+	if err != nil {
+		return fmt.Errorf("read frequencies: %w", err)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return errors.New("invalid byte count")
+	}
+	if limit := chunks.Remaining(dst); count > uint64(limit) {
+		return fmt.Errorf("byte count %d: %w", count, chunks.ErrLimit)
+	}
+
+	// slots maps every position of the probability scale to its symbol.
+	var slots [probScale]byte
+	var starts [table.ByteAlphabetSize]uint32
+	var start uint32
+	for symbol, freq := range freqs {
+		starts[symbol] = start
+		for i := start; i < start+freq; i++ {
+			slots[i] = byte(symbol)
+		}
+		start += freq
+	}
+
+	var stateBytes [stateSize]byte
+	if _, err := io.ReadFull(br, stateBytes[:]); err != nil {
+		return fmt.Errorf("read coder state: %w", truncated(err))
+	}
+	state := binary.BigEndian.Uint32(stateBytes[:])
+
+	out := bufio.NewWriter(dst)
+	for decoded := uint64(0); decoded < count; decoded++ {
+		if state < lowerBound || state >= lowerBound<<8 {
+			return fmt.Errorf("symbol %d: invalid coder state", decoded)
+		}
+		slot := state & (probScale - 1)
+		symbol := slots[slot]
+		state = freqs[symbol]*(state>>ProbBits) + slot - starts[symbol]
+		for state < lowerBound {
+			b, err := br.ReadByte()
+			if err != nil {
+				return fmt.Errorf("symbol %d: %w", decoded, truncated(err))
+			}
+			state = state<<8 | uint32(b)
+		}
+		_ = out.WriteByte(symbol)
+	}
+
+	if state != lowerBound {
+		return errors.New("coder did not end in its initial state")
+	}
+	if err := expectEnd(br); err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// expectEnd returns an error unless br has no more data.
+func expectEnd(br *bufio.Reader) error {
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last symbol")
+	}
+	return nil
+}
+
+// truncated reports running out of data as io.ErrUnexpectedEOF, since the byte
+// count says more should follow.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
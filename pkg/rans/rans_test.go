@@ -0,0 +1,85 @@
+package rans
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/huffman"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 200000)
+	rng.Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "single symbol", data: bytes.Repeat([]byte("a"), 10000)},
+		{name: "text", data: []byte("abracadabra, said the wizard\n")},
+		{name: "every byte", data: append(all, all...)},
+		{name: "random", data: random},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded, decoded bytes.Buffer
+			if err := Encode(&encoded, bytes.NewReader(tt.data)); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			if err := Decode(&decoded, &encoded); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tt.data) {
+				t.Errorf("Decode(Encode()) returned %d bytes, want %d matching bytes", decoded.Len(), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestEncodeBeatsHuffmanOnSkewedData(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = 'a'
+		if rng.Intn(100) < 5 {
+			data[i] = byte('b' + rng.Intn(4))
+		}
+	}
+
+	var ans, prefix bytes.Buffer
+	if err := Encode(&ans, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if err := huffman.Encode(&prefix, bytes.NewReader(data)); err != nil {
+		t.Fatalf("huffman.Encode() failed: %v", err)
+	}
+	if ans.Len() >= prefix.Len()/2 {
+		t.Errorf("rANS wrote %d bytes, want less than half of Huffman's %d", ans.Len(), prefix.Len())
+	}
+}
+
+func TestDecodeRejectsDamagedStreams(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, bytes.NewReader(bytes.Repeat([]byte("truncate me, please. "), 20))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	stream := encoded.Bytes()
+
+	err := Decode(io.Discard, bytes.NewReader(stream[:len(stream)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode() of a truncated stream: error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := Decode(io.Discard, bytes.NewReader(append(bytes.Clone(stream), 0))); err == nil {
+		t.Error("Decode() with trailing data succeeded, want error")
+	}
+}
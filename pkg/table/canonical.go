@@ -0,0 +1,122 @@
+// Package table also provides canonical prefix codes. A canonical code is fully
+// determined by the code length of each symbol, so a table can be stored as a
+// short list of lengths and rebuilt bit-for-bit by the reader.
+package table
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MaxCodeLength is the longest code supported by canonical tables. It lets
+// MarshalLengths store each length in 4 bits.
+const MaxCodeLength = 15
+
+// CodeLengths maps symbols to the bit length of their codes. Symbols with a
+// length of zero have no code.
+type CodeLengths map[rune]uint8
+
+// CodeLengths returns the length of every code in the table.
+func (ct CodeTable) CodeLengths() CodeLengths {
+	lengths := make(CodeLengths, len(ct))
+	for symbol, code := range ct {
+		lengths[symbol] = code.Length
+	}
+	return lengths
+}
+
+// Canonical converts the table into the canonical prefix code with the same code
+// lengths. Returns an error if the table is not a valid prefix code.
+func (ct CodeTable) Canonical() (CodeTable, error) {
+	for symbol, code := range ct {
+		if code.Length == 0 || code.Length > MaxCodeLength {
+			return nil, fmt.Errorf("invalid code length %d for symbol %d", code.Length, symbol)
+		}
+	}
+	return ct.CodeLengths().CanonicalCodes()
+}
+
+// CanonicalCodes assigns canonical codes to the lengths: symbols are sorted by code
+// length, then by value, and each code is the previous one plus one, shifted left
+// whenever the length grows. Returns an error for lengths above MaxCodeLength or
+// lengths that oversubscribe the code space and so cannot form a prefix code.
+func (cl CodeLengths) CanonicalCodes() (CodeTable, error) {
+	symbols := make([]rune, 0, len(cl))
+	for symbol, length := range cl {
+		if length > MaxCodeLength {
+			return nil, fmt.Errorf("code length %d for symbol %d exceeds %d", length, symbol, MaxCodeLength)
+		}
+		if length > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if cl[symbols[i]] != cl[symbols[j]] {
+			return cl[symbols[i]] < cl[symbols[j]]
+		}
+		return symbols[i] < symbols[j]
+	})
+
+	codes := make(CodeTable, len(symbols))
+	var code uint64
+	var prevLength uint8
+	for _, symbol := range symbols {
+		length := cl[symbol]
+		code <<= length - prevLength
+		if code >= 1<<length {
+			return nil, errors.New("code lengths oversubscribe the code space")
+		}
+		codes[symbol] = Code{Bits: code, Length: length}
+		code++
+		prevLength = length
+	}
+
+	return codes, nil
+}
+
+// MarshalLengths serialises the code lengths of symbols 0 to alphabetSize-1 as a
+// list of 4-bit lengths, two per byte, high nibble first. Returns an error for
+// symbols outside the alphabet or lengths above MaxCodeLength.
+func MarshalLengths(cl CodeLengths, alphabetSize int) ([]byte, error) {
+	buf := make([]byte, (alphabetSize+1)/2)
+	for symbol, length := range cl {
+		if length == 0 {
+			continue
+		}
+		if symbol < 0 || int(symbol) >= alphabetSize {
+			return nil, fmt.Errorf("symbol %d outside alphabet of %d symbols", symbol, alphabetSize)
+		}
+		if length > MaxCodeLength {
+			return nil, fmt.Errorf("code length %d for symbol %d exceeds %d", length, symbol, MaxCodeLength)
+		}
+		if symbol%2 == 0 {
+			buf[symbol/2] |= length << 4
+		} else {
+			buf[symbol/2] |= length
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalLengths is the inverse of MarshalLengths. It reads the lengths of
+// alphabetSize symbols from the start of data and returns them with the number
+// of bytes consumed.
+func UnmarshalLengths(data []byte, alphabetSize int) (CodeLengths, int, error) {
+	size := (alphabetSize + 1) / 2
+	if len(data) < size {
+		return nil, 0, fmt.Errorf("truncated code lengths: want %d bytes, got %d", size, len(data))
+	}
+
+	lengths := make(CodeLengths)
+	for symbol := 0; symbol < alphabetSize; symbol++ {
+		length := data[symbol/2] >> 4
+		if symbol%2 == 1 {
+			length = data[symbol/2] & 0x0F
+		}
+		if length > 0 {
+			lengths[rune(symbol)] = length
+		}
+	}
+	return lengths, size, nil
+}
@@ -0,0 +1,132 @@
+package table
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	codes, err := CodeLengths{'a': 2, 'b': 1, 'c': 3, 'd': 3}.CanonicalCodes()
+	if err != nil {
+		t.Fatalf("CanonicalCodes() failed: %v", err)
+	}
+
+	want := CodeTable{'b': {0b0, 1}, 'a': {0b10, 2}, 'c': {0b110, 3}, 'd': {0b111, 3}}
+	for symbol, code := range want {
+		if codes[symbol] != code {
+			t.Errorf("code for %q = %v, want %v", symbol, codes[symbol], code)
+		}
+	}
+}
+
+func TestCanonicalKeepsFixedTableLengths(t *testing.T) {
+	fixed := BuildCodeTable()
+	canonical, err := fixed.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical() failed: %v", err)
+	}
+
+	for symbol, code := range fixed {
+		if canonical[symbol].Length != code.Length {
+			t.Errorf("code length for %d = %d, want %d", symbol, canonical[symbol].Length, code.Length)
+		}
+	}
+	for symbol, code := range canonical {
+		for other, otherCode := range canonical {
+			if symbol != other && strings.HasPrefix(otherCode.String(), code.String()) {
+				t.Errorf("code %v for %d is a prefix of %v for %d", code, symbol, otherCode, other)
+			}
+		}
+	}
+}
+
+func TestCanonicalRejectsOversubscribedLengths(t *testing.T) {
+	if _, err := (CodeLengths{'a': 1, 'b': 1, 'c': 1}).CanonicalCodes(); err == nil {
+		t.Errorf("CanonicalCodes() expected error for oversubscribed lengths")
+	}
+}
+
+func TestMarshalLengthsRoundTrip(t *testing.T) {
+	lengths := CodeLengths{0: 3, 1: 15, 4: 1, 6: 7}
+
+	data, err := MarshalLengths(lengths, 7)
+	if err != nil {
+		t.Fatalf("MarshalLengths() failed: %v", err)
+	}
+	if len(data) != 4 {
+		t.Errorf("MarshalLengths() size = %d, want 4", len(data))
+	}
+
+	got, size, err := UnmarshalLengths(data, 7)
+	if err != nil {
+		t.Fatalf("UnmarshalLengths() failed: %v", err)
+	}
+	if size != len(data) || len(got) != len(lengths) {
+		t.Fatalf("UnmarshalLengths() = %v, %d, want %v, %d", got, size, lengths, len(data))
+	}
+	for symbol, length := range lengths {
+		if got[symbol] != length {
+			t.Errorf("length for %d = %d, want %d", symbol, got[symbol], length)
+		}
+	}
+
+	if _, err := MarshalLengths(CodeLengths{7: 1}, 7); err == nil {
+		t.Errorf("MarshalLengths() expected error for symbol outside the alphabet")
+	}
+}
+
+func TestNormalizeFrequencies(t *testing.T) {
+	skewed := make(Frequencies, ByteAlphabetSize)
+	skewed['a'], skewed['b'], skewed['c'] = 1<<40, 1, 1
+	everyByte := CountBytes([]byte("every byte value occurs at least once"))
+	for symbol := range everyByte {
+		everyByte[symbol]++
+	}
+
+	tests := []struct {
+		name  string
+		freqs Frequencies
+	}{
+		{"single symbol", CountBytes([]byte("aaaaaaa"))},
+		{"rounding shortfall", CountBytes([]byte("abc"))},
+		{"rare symbols rounded up", skewed},
+		{"every byte", everyByte},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			norm, err := tt.freqs.Normalize(12)
+			if err != nil {
+				t.Fatalf("Normalize() failed: %v", err)
+			}
+			var sum uint32
+			for symbol, n := range norm {
+				if (n == 0) != (tt.freqs[symbol] == 0) {
+					t.Errorf("symbol %d: frequency %d normalized to %d", symbol, tt.freqs[symbol], n)
+				}
+				sum += n
+			}
+			if sum != 1<<12 {
+				t.Errorf("normalized frequencies sum to %d, want %d", sum, 1<<12)
+			}
+
+			data, err := MarshalFrequencies(norm)
+			if err != nil {
+				t.Fatalf("MarshalFrequencies() failed: %v", err)
+			}
+			got, err := UnmarshalFrequencies(bytes.NewReader(data), 12)
+			if err != nil {
+				t.Fatalf("UnmarshalFrequencies() failed: %v", err)
+			}
+			if !slices.Equal(got, norm) {
+				t.Errorf("UnmarshalFrequencies() = %v, want %v", got, norm)
+			}
+		})
+	}
+
+	if _, err := make(Frequencies, ByteAlphabetSize).Normalize(12); err == nil {
+		t.Error("Normalize() without symbols succeeded, want error")
+	}
+}
@@ -0,0 +1,19 @@
+package table
+
+import "fmt"
+
+// Code is a prefix code stored as a (bits, length) pair: the code is the low
+// Length bits of Bits, most significant bit first. It is the form used by
+// chunks.BitWriter.
+type Code struct {
+	Bits   uint64
+	Length uint8
+}
+
+// CodeTable maps symbols to their codes as (bits, length) pairs.
+type CodeTable map[rune]Code
+
+// String returns the code as a binary string such as "0110".
+func (c Code) String() string {
+	return fmt.Sprintf("%0*b", int(c.Length), c.Bits)
+}
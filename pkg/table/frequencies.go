@@ -0,0 +1,147 @@
+// Package table also provides the symbol model shared by the statistical codecs:
+// symbol frequencies counted over the input, from which the Huffman codec derives
+// code lengths and the ANS codec a normalized frequency table.
+package table
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ByteAlphabetSize is the number of symbols of a model over bytes.
+const ByteAlphabetSize = 256
+
+// Frequencies holds the number of occurrences of every symbol, indexed by symbol.
+type Frequencies []uint64
+
+// CountBytes returns the frequency of every byte value in data.
+func CountBytes(data []byte) Frequencies {
+	freqs := make(Frequencies, ByteAlphabetSize)
+	for _, b := range data {
+		freqs[b]++
+	}
+	return freqs
+}
+
+// Normalize scales the frequencies to integers summing to exactly 1<<bits, as
+// needed by ANS coders, keeping every symbol that occurs at a frequency of at
+// least 1. Returns an error if no symbol occurs or there are more symbols than
+// 1<<bits can hold.
+func (f Frequencies) Normalize(bits uint) ([]uint32, error) {
+	target := uint64(1) << bits
+	var total, used uint64
+	for _, n := range f {
+		total += n
+		if n > 0 {
+			used++
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("no symbols to normalize")
+	}
+	if used > target {
+		return nil, fmt.Errorf("%d symbols do not fit in %d-bit frequencies", used, bits)
+	}
+
+	norm := make([]uint32, len(f))
+	var sum uint64
+	largest := -1
+	for symbol, n := range f {
+		if n == 0 {
+			continue
+		}
+		scaled := max(n*target/total, 1)
+		norm[symbol] = uint32(scaled)
+		sum += scaled
+		if largest < 0 || n > f[largest] {
+			largest = symbol
+		}
+	}
+
+	// Rounding down leaves a shortfall, which goes to the most frequent symbol.
+	// Rounding rare symbols up to 1 may overshoot instead; take the excess from
+	// whichever symbol is largest at the time, which costs the least.
+	if sum <= target {
+		norm[largest] += uint32(target - sum)
+		return norm, nil
+	}
+	for ; sum > target; sum-- {
+		biggest := 0
+		for symbol := range norm {
+			if norm[symbol] > norm[biggest] {
+				biggest = symbol
+			}
+		}
+		norm[biggest]--
+	}
+	return norm, nil
+}
+
+// MarshalFrequencies serialises a normalized frequency table as the number of
+// symbols with a non-zero frequency, as a uvarint, followed by each such symbol
+// as a byte and its frequency minus one as a uvarint. Returns an error for
+// symbols that do not fit in a byte.
+func MarshalFrequencies(norm []uint32) ([]byte, error) {
+	var count uint64
+	for symbol, n := range norm {
+		if n == 0 {
+			continue
+		}
+		if symbol >= ByteAlphabetSize {
+			return nil, fmt.Errorf("symbol %d outside alphabet of %d symbols", symbol, ByteAlphabetSize)
+		}
+		count++
+	}
+
+	buf := binary.AppendUvarint(nil, count)
+	for symbol, n := range norm {
+		if n > 0 {
+			buf = append(buf, byte(symbol))
+			buf = binary.AppendUvarint(buf, uint64(n-1))
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalFrequencies is the inverse of MarshalFrequencies. It reads a table of
+// ByteAlphabetSize symbols from r and checks that the frequencies sum to exactly
+// 1<<bits, with symbols in increasing order.
+func UnmarshalFrequencies(r io.ByteReader, bits uint) ([]uint32, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("read symbol count: %w", err)
+	}
+	if count == 0 || count > ByteAlphabetSize {
+		return nil, fmt.Errorf("invalid symbol count %d", count)
+	}
+
+	target := uint64(1) << bits
+	norm := make([]uint32, ByteAlphabetSize)
+	var sum uint64
+	previous := -1
+	for i := uint64(0); i < count; i++ {
+		symbol, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("read symbol: %w", err)
+		}
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("read frequency: %w", err)
+		}
+		if int(symbol) <= previous {
+			return nil, fmt.Errorf("symbol %d out of order", symbol)
+		}
+		if n >= target {
+			return nil, fmt.Errorf("frequency %d of symbol %d exceeds %d", n+1, symbol, target)
+		}
+		previous = int(symbol)
+		norm[symbol] = uint32(n + 1)
+		sum += n + 1
+	}
+	if sum != target {
+		return nil, fmt.Errorf("frequencies sum to %d, want %d", sum, target)
+	}
+	return norm, nil
+}
@@ -1,85 +1,114 @@
-// Package table provides functionality for encoding characters into binary strings
-// using a predefined encoding table. This is useful for compression or custom encoding schemes.
+// Package table provides functionality for encoding characters into prefix codes
+// using a predefined code table. This is useful for compression or custom encoding schemes.
 package table
 
-// EncodingTable maps runes (characters) to their corresponding binary string representations.
-// The keys are Unicode characters, and the values are binary strings of varying lengths.
-type EncodingTable map[rune]string
+const (
+	// Escape is a control symbol that is followed by EscapeBits literal bits holding one raw
+	// byte. Any byte not covered by the table, including parts of multi-byte UTF-8 sequences
+	// and invalid UTF-8, is encoded as Escape plus the byte itself.
+	Escape rune = -1
 
-// BuildEncodingTable initializes and returns a predefined EncodingTable.
-// The table includes mappings for:
+	// Shift is a control symbol marking the next letter as uppercase.
+	Shift rune = -2
+
+	// CapsLock is a control symbol that toggles uppercase mode: while it is on, every
+	// letter is uppercase until the next CapsLock. Other symbols are unaffected.
+	CapsLock rune = -3
+
+	// EscapeBits is the number of literal bits that follow an Escape code.
+	EscapeBits = 8
+)
+
+// BuildCodeTable initializes and returns the predefined CodeTable.
+// The table includes codes for:
 //   - Basic lowercase letters (e.g., 'e', 't', 'a')
 //   - Digits (0-9)
 //   - Special characters (e.g., ' ', '.', ',', '!')
-//   - Uppercase markers and additional letters (e.g., 'd', 'l', 'c')
+//   - Additional letters (e.g., 'd', 'l', 'c')
+//   - Whitespace and punctuation common in source files (e.g., '\n', '\t', '"')
+//   - The Escape, Shift and CapsLock control symbols
 //
 // Example:
 //
-//	'e' -> "000"
-//	't' -> "0010"
-//	' ' -> "1010010"
+//	'e' -> 000
+//	't' -> 0010
+//	' ' -> 1010010
 //
 // This table is designed for efficient encoding of common characters in English text.
-func BuildEncodingTable() EncodingTable {
-	return EncodingTable{
+func BuildCodeTable() CodeTable {
+	return CodeTable{
 		// Basic letters
-		'e': "000",
-		't': "0010",
-		'a': "0011",
-		'o': "0100",
-		'n': "0101",
-		's': "0110",
-		'r': "0111",
-		'h': "10000",
-		'i': "10001",
+		'e': {0b000, 3},
+		't': {0b0010, 4},
+		'a': {0b0011, 4},
+		'o': {0b0100, 4},
+		'n': {0b0101, 4},
+		's': {0b0110, 4},
+		'r': {0b0111, 4},
+		'h': {0b10000, 5},
+		'i': {0b10001, 5},
 
 		// Digits
-		'0': "1001000",
-		'1': "1001001",
-		'2': "1001010",
-		'3': "1001011",
-		'4': "1001100",
-		'5': "1001101",
-		'6': "1001110",
-		'7': "1001111",
-		'8': "1010000",
-		'9': "1010001",
+		'0': {0b1001000, 7},
+		'1': {0b1001001, 7},
+		'2': {0b1001010, 7},
+		'3': {0b1001011, 7},
+		'4': {0b1001100, 7},
+		'5': {0b1001101, 7},
+		'6': {0b1001110, 7},
+		'7': {0b1001111, 7},
+		'8': {0b1010000, 7},
+		'9': {0b1010001, 7},
 
 		// Special characters
-		' ': "1010010",
-		'.': "1010011",
-		',': "1010100",
-		'!': "1010101",
-		'?': "1010110",
-		'-': "1010111",
-		'_': "1011000",
-		'@': "1011001",
-		'#': "1011010",
-		'$': "1011011",
-		'%': "1011100",
-		'^': "1011101",
-		'&': "1011110",
-		'*': "1011111",
-		'(': "1100000",
-		')': "1100001",
+		' ': {0b1010010, 7},
+		'.': {0b1010011, 7},
+		',': {0b1010100, 7},
+		'!': {0b1010101, 7},
+		'?': {0b1010110, 7},
+		'-': {0b1010111, 7},
+		'_': {0b1011000, 7},
+		'@': {0b1011001, 7},
+		'#': {0b1011010, 7},
+		'$': {0b1011011, 7},
+		'%': {0b1011100, 7},
+		'^': {0b1011101, 7},
+		'&': {0b1011110, 7},
+		'*': {0b1011111, 7},
+		'(': {0b1100000, 7},
+		')': {0b1100001, 7},
+
+		// Additional letters
+		'd': {0b1100010, 7},
+		'l': {0b1100011, 7},
+		'c': {0b1100100, 7},
+		'u': {0b1100101, 7},
+		'm': {0b1100110, 7},
+		'w': {0b1100111, 7},
+		'f': {0b1101000, 7},
+		'g': {0b1101001, 7},
+		'y': {0b1101010, 7},
+		'p': {0b1101011, 7},
+		'b': {0b1101100, 7},
+		'v': {0b1101101, 7},
+		'k': {0b1101110, 7},
+		'j': {0b1101111, 7},
+		'x': {0b1110000, 7},
+		'q': {0b1110001, 7},
+		'z': {0b1110010, 7},
+
+		// Whitespace and punctuation common in source files
+		'\n': {0b1110011, 7},
+		'\t': {0b1110100, 7},
+		'"':  {0b1110101, 7},
+		'\'': {0b1110110, 7},
+		'/':  {0b1110111, 7},
+		':':  {0b1111000, 7},
+		';':  {0b1111001, 7},
 
-		// Uppercase markers and letters
-		'd': "1100010",
-		'l': "1100011",
-		'c': "1100100",
-		'u': "1100101",
-		'm': "1100110",
-		'w': "1100111",
-		'f': "1101000",
-		'g': "1101001",
-		'y': "1101010",
-		'p': "1101011",
-		'b': "1101100",
-		'v': "1101101",
-		'k': "1101110",
-		'j': "1101111",
-		'x': "1110000",
-		'q': "1110001",
-		'z': "1110010",
+		// Control symbols
+		Escape:   {0b1111010, 7},
+		Shift:    {0b1111011, 7},
+		CapsLock: {0b1111100, 7},
 	}
 }
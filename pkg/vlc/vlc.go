@@ -0,0 +1,330 @@
+// Package vlc implements the fixed-table variable-length codec. Text is written
+// with the codes from table.BuildCodeTable, uppercase letters with the Shift
+// and CapsLock control codes, and anything else byte by byte behind the Escape
+// code, so every input round-trips exactly.
+//
+// An encoded stream is the number of symbols as a uvarint followed by the packed
+// codes, with the final byte padded by zero bits.
+package vlc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+)
+
+// fixedTable is the fixed code table prepared for coding, with a direct lookup
+// array for ASCII and the lookup table decoding it.
+type fixedTable struct {
+	codes  table.CodeTable
+	ascii  [utf8.RuneSelf]table.Code
+	lookup *decodingTree.LookupTable
+}
+
+// loadFixedTable prepares table.BuildCodeTable once and checks it has every
+// control code the codec relies on.
+var loadFixedTable = sync.OnceValues(func() (*fixedTable, error) {
+	codes := table.BuildCodeTable()
+	for _, control := range []rune{table.Escape, table.Shift, table.CapsLock} {
+		if _, ok := codes[control]; !ok {
+			return nil, fmt.Errorf("code table has no code for control symbol %d", control)
+		}
+	}
+
+	lookup, err := decodingTree.BuildLookupTable(codes)
+	if err != nil {
+		return nil, fmt.Errorf("build decoding table: %w", err)
+	}
+
+	ft := &fixedTable{codes: codes, lookup: lookup}
+	for symbol, code := range codes {
+		if symbol >= 0 && symbol < utf8.RuneSelf {
+			ft.ascii[symbol] = code
+		}
+	}
+	return ft, nil
+})
+
+// Encode reads src to the end, encodes it with the fixed code table and
+// writes the symbol count followed by the packed codes to dst.
+//
+// Parameters:
+//   - dst: The writer receiving the encoded stream.
+//   - src: The reader providing the data to encode.
+//
+// Returns:
+//   - error: An error if reading, encoding or writing fails.
+func Encode(dst io.Writer, src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+	ft, err := loadFixedTable()
+	if err != nil {
+		return err
+	}
+
+	var packed bytes.Buffer
+	bw := chunks.NewBitWriter(&packed)
+	symbols, err := encodeSymbols(bw, ft, data)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	if _, err := dst.Write(binary.AppendUvarint(nil, symbols)); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	if _, err := packed.WriteTo(dst); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an encoded stream from src, decodes exactly the number of symbols
+// recorded at its start and writes the original data to dst. Only the zero bits
+// padding the final byte may follow the last symbol. For a chunks.LimitedWriter,
+// a symbol count too large for the limit is rejected before decoding, and
+// decoding stops with chunks.ErrLimit at the first symbol past the limit.
+//
+// Parameters:
+//   - dst: The writer receiving the decoded data.
+//   - src: The reader providing the encoded stream.
+//
+// Returns:
+//   - error: An error if the stream is truncated or corrupt, or if writing fails.
+func Decode(dst io.Writer, src io.Reader) error {
+	ft, err := loadFixedTable()
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(src)
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return fmt.Errorf("read symbol count: %w", err)
+	}
+	// Every byte takes at most two symbols: a Shift or CapsLock code only
+	// comes before a letter.
+	limit := chunks.Remaining(dst)
+	if count-count/2 > uint64(limit) {
+		return fmt.Errorf("symbol count %d: %w", count, chunks.ErrLimit)
+	}
+
+	out := bufio.NewWriter(dst)
+	bits := chunks.NewBitReader(br)
+	if err := decodeSymbols(out, ft.lookup, bits, count, limit); err != nil {
+		return fmt.Errorf("decode binary data: %w", err)
+	}
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after last symbol")
+	}
+
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// encodeSymbols writes the codes for data to bw using the fixed table:
+//   - An uppercase letter whose lowercase form is in the table is written as
+//     the table.Shift code followed by the lowercase letter. Runs of two or
+//     more such letters are instead wrapped in table.CapsLock codes, with
+//     non-letters such as spaces and digits allowed inside the run.
+//   - Characters missing from the table and invalid UTF-8 are written byte by
+//     byte, each byte as the table.Escape code followed by the byte's
+//     table.EscapeBits literal bits.
+//
+// Parameters:
+//   - bw: The bit writer receiving the codes.
+//   - ft: The prepared fixed table.
+//   - data: The input to encode.
+//
+// Returns:
+//   - uint64: The number of symbols encoded, counting each control code once.
+//   - error: An error if writing fails.
+func encodeSymbols(bw *chunks.BitWriter, ft *fixedTable, data []byte) (uint64, error) {
+	var symbols uint64
+	var err error
+	// write writes bits that are not a symbol of their own, such as the
+	// literal byte after an escape; emit writes a symbol's code and counts it,
+	// so every letter, character and control code is counted exactly once.
+	write := func(bits uint64, length uint) {
+		if err == nil {
+			err = bw.WriteBits(bits, length)
+		}
+	}
+	emit := func(code table.Code) {
+		write(code.Bits, uint(code.Length))
+		symbols++
+	}
+
+	capsLock := false
+	for i := 0; i < len(data) && err == nil; {
+		// Fast path for ASCII that is in the table and needs no case handling
+		if b := data[i]; b < utf8.RuneSelf && !capsLock && ft.ascii[b].Length > 0 {
+			emit(ft.ascii[b])
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data[i:])
+		rest := data[i+size:]
+
+		if lower, ok := lowerCase(ft.codes, r); ok {
+			if !capsLock {
+				next, _ := utf8.DecodeRune(rest)
+				if _, ok := lowerCase(ft.codes, next); ok {
+					emit(ft.codes[table.CapsLock])
+					capsLock = true
+				} else {
+					emit(ft.codes[table.Shift])
+				}
+			}
+			emit(ft.codes[lower])
+		} else if code, ok := ft.codes[r]; ok && size == utf8.RuneLen(r) {
+			if capsLock && isCased(r) {
+				emit(ft.codes[table.CapsLock])
+				capsLock = false
+			}
+			emit(code)
+		} else {
+			for _, b := range data[i : i+size] {
+				emit(ft.codes[table.Escape])
+				write(uint64(b), table.EscapeBits)
+			}
+		}
+
+		i += size
+	}
+
+	return symbols, err
+}
+
+// lowerCase reports whether r is an uppercase letter that can be written as a
+// case control code plus a table letter, and returns that letter. Letters whose
+// case does not round-trip (e.g. 'İ') are rejected so decoding stays lossless.
+func lowerCase(codes table.CodeTable, r rune) (rune, bool) {
+	lower := unicode.ToLower(r)
+	if lower == r || unicode.ToUpper(lower) != r {
+		return 0, false
+	}
+	_, ok := codes[lower]
+	return lower, ok
+}
+
+// isCased reports whether a decoder in caps-lock mode would uppercase r, in which
+// case caps lock must be released before r is written.
+func isCased(r rune) bool {
+	upper := unicode.ToUpper(r)
+	return upper != r && unicode.ToLower(upper) == r
+}
+
+// decodeSymbols decodes count symbols from bits using the lookup table and
+// interprets the control symbols:
+//   - table.Escape is replaced by the raw byte held in the table.EscapeBits
+//     bits that follow it.
+//   - table.Shift uppercases the next letter.
+//   - table.CapsLock toggles uppercasing of every letter until the next
+//     CapsLock.
+//
+// Parameters:
+//   - out: The writer receiving the decoded data, which may contain escaped
+//     non-UTF-8 bytes.
+//   - lookup: The lookup table built from the code table.
+//   - bits: The bit reader positioned at the first code.
+//   - count: The number of symbols to decode.
+//   - limit: The number of bytes that may be written to out.
+//
+// Returns:
+//   - error: An error if the data is truncated, contains an unknown code or a
+//     shift that is not followed by a letter, if it decodes to more than limit
+//     bytes (chunks.ErrLimit), or if writing fails.
+func decodeSymbols(out *bufio.Writer, lookup *decodingTree.LookupTable, bits *chunks.BitReader, count uint64, limit int64) error {
+	var shift, capsLock bool
+	var written int64
+
+	for decoded := uint64(0); decoded < count; decoded++ {
+		symbol, err := lookup.ReadSymbol(bits)
+		if err != nil {
+			return fmt.Errorf("symbol %d: %w", decoded, truncated(err))
+		}
+
+		switch symbol {
+		case table.Shift:
+			if shift {
+				return fmt.Errorf("symbol %d: repeated shift", decoded)
+			}
+			shift = true
+		case table.CapsLock:
+			capsLock = !capsLock
+		case table.Escape:
+			if shift {
+				return fmt.Errorf("symbol %d: shift followed by escaped byte", decoded)
+			}
+			value, err := bits.ReadBits(table.EscapeBits)
+			if err != nil {
+				return fmt.Errorf("symbol %d: escaped byte: %w", decoded, truncated(err))
+			}
+			if written++; written > limit {
+				return fmt.Errorf("symbol %d: %w", decoded, chunks.ErrLimit)
+			}
+			if err := out.WriteByte(byte(value)); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+		default:
+			if shift || capsLock {
+				upper, cased := upperCase(symbol)
+				if shift && !cased {
+					return fmt.Errorf("symbol %d: shift followed by %q", decoded, symbol)
+				}
+				if cased {
+					symbol = upper
+				}
+			}
+			if written += int64(utf8.RuneLen(symbol)); written > limit {
+				return fmt.Errorf("symbol %d: %w", decoded, chunks.ErrLimit)
+			}
+			if _, err := out.WriteRune(symbol); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+			shift = false
+		}
+	}
+
+	if shift {
+		return errors.New("shift at end of data")
+	}
+	if bits.Buffered() >= chunks.ChunkSize {
+		return fmt.Errorf("unexpected %d trailing bits after last symbol", bits.Buffered())
+	}
+	return nil
+}
+
+// upperCase returns the uppercase form of a table letter and reports whether the
+// letter has one that maps back to it, mirroring the encoder's case rules.
+func upperCase(r rune) (rune, bool) {
+	upper := unicode.ToUpper(r)
+	return upper, upper != r && unicode.ToLower(upper) == r
+}
+
+// truncated reports running out of data as io.ErrUnexpectedEOF, since the
+// symbol count says more codes should follow.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,272 @@
+// Package vlcList provides the `list` command, which prints the entries of a
+// `.vlc` archive or single-file stream without unpacking them.
+package vlcList
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/flexer2006/simpleArchiver-golang/internal/application"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcUnpack"
+	"github.com/spf13/cobra"
+)
+
+// timeLayout is the format of timestamps in the table output.
+const timeLayout = "2006-01-02 15:04"
+
+var (
+	// longOutput adds mode and ownership columns. Set by the --long flag.
+	longOutput bool
+
+	// jsonOutput prints the entries as a JSON array instead of a table. Set by the --json flag.
+	jsonOutput bool
+)
+
+// ListCmd is the Cobra command for listing the contents of an archive.
+// Usage: list [archive_path] [pattern...]
+// Short: List the contents of an archive.
+var ListCmd = &cobra.Command{
+	Use:   "list [archive_path] [pattern...]",
+	Short: "List the contents of an archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		application.HandlePanic(func() {
+			err := application.HandleError(func() error {
+				if len(args) == 0 || args[0] == "" {
+					return application.ErrEmptyPath
+				}
+				return list(cmd.OutOrStdout(), args[0], args[1:])
+			})
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		})
+	},
+}
+
+// list prints the entries of the archive at archivePath whose names match one of
+// the glob patterns, or every entry when there are none, in the format selected
+// by --long and --json.
+func list(w io.Writer, archivePath string, patterns []string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("Warning: failed to close file: %v", closeErr)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat file: %w", err)
+	}
+	archive, err := vlcUnpack.OpenArchive(file, info.Size())
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+
+	var entries []container.Entry
+	for _, e := range archive.Entries {
+		if e.Name == "" {
+			e.Name = vlcUnpack.SingleFileName(archivePath)
+		}
+		ok, err := matches(e.Name, patterns)
+		if err != nil {
+			return err
+		}
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+
+	if jsonOutput {
+		return printJSON(w, entries)
+	}
+	return printTable(w, entries, longOutput)
+}
+
+// matches reports whether name matches one of the patterns. Patterns without a
+// slash are also matched against the base name, so "*.go" finds Go files at any depth.
+func matches(name string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	for _, pattern := range patterns {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+		ok, err := path.Match(pattern, subject)
+		if err != nil {
+			return false, fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// printTable prints one aligned row per entry, followed by a total line. With
+// long the mode, owner and group are included, and symlinks are not expanded.
+func printTable(w io.Writer, entries []container.Entry, long bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := "SIZE\tPACKED\tRATIO\tCODEC\tMODIFIED\tNAME"
+	if long {
+		header = "MODE\tOWNER\tGROUP\t" + header
+	}
+	fmt.Fprintln(tw, header)
+
+	var size, packed uint64
+	for _, e := range entries {
+		size += e.Size
+		packed += e.PackedSize
+
+		if long {
+			fmt.Fprintf(tw, "%v\t%s\t%s\t", e.Mode, owner(e.User, e.UID), owner(e.Group, e.GID))
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\n",
+			e.Size, e.PackedSize, ratio(e.Size, e.PackedSize), codecName(e.Codec), timestamp(e.ModTime), displayName(e))
+	}
+
+	if long {
+		fmt.Fprint(tw, "\t\t\t")
+	}
+	noun := "entries"
+	if len(entries) == 1 {
+		noun = "entry"
+	}
+	fmt.Fprintf(tw, "%d\t%d\t%s\t\t\t%d %s\n", size, packed, ratio(size, packed), len(entries), noun)
+	return tw.Flush()
+}
+
+// jsonEntry is the JSON form of an entry printed with --json.
+type jsonEntry struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Size       uint64            `json:"size"`
+	PackedSize uint64            `json:"packedSize"`
+	Ratio      float64           `json:"ratio"`
+	Codec      string            `json:"codec"`
+	Mode       string            `json:"mode"`
+	ModTime    *time.Time        `json:"modTime,omitempty"`
+	AccessTime *time.Time        `json:"accessTime,omitempty"`
+	UID        uint32            `json:"uid"`
+	GID        uint32            `json:"gid"`
+	User       string            `json:"user,omitempty"`
+	Group      string            `json:"group,omitempty"`
+	Xattrs     map[string][]byte `json:"xattrs,omitempty"`
+	CRC32C     string            `json:"crc32c"`
+	SHA256     string            `json:"sha256,omitempty"`
+}
+
+// printJSON prints the entries as an indented JSON array.
+func printJSON(w io.Writer, entries []container.Entry) error {
+	out := make([]jsonEntry, 0, len(entries))
+	for _, e := range entries {
+		je := jsonEntry{
+			Name:       e.Name,
+			Type:       entryType(e.Mode),
+			Size:       e.Size,
+			PackedSize: e.PackedSize,
+			Codec:      codecName(e.Codec),
+			Mode:       e.Mode.String(),
+			UID:        e.UID,
+			GID:        e.GID,
+			User:       e.User,
+			Group:      e.Group,
+			Xattrs:     e.Xattrs,
+			CRC32C:     fmt.Sprintf("%08x", e.CRC32C),
+			SHA256:     hex.EncodeToString(e.SHA256),
+		}
+		if e.Size > 0 {
+			je.Ratio = float64(e.PackedSize) / float64(e.Size)
+		}
+		if !e.ModTime.IsZero() {
+			je.ModTime = &e.ModTime
+		}
+		if !e.AccessTime.IsZero() {
+			je.AccessTime = &e.AccessTime
+		}
+		out = append(out, je)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// entryType names the kind of file an entry holds.
+func entryType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "file"
+	}
+}
+
+// displayName returns the entry name, marking directories with a trailing slash.
+func displayName(e container.Entry) string {
+	if e.Mode.IsDir() {
+		return e.Name + "/"
+	}
+	return e.Name
+}
+
+// ratio formats the packed size as a percentage of the original size.
+func ratio(size, packed uint64) string {
+	if size == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(packed)/float64(size))
+}
+
+// codecName returns the name of the codec with the given ID.
+func codecName(id uint8) string {
+	c, err := codec.ByID(id)
+	if err != nil {
+		return fmt.Sprintf("unknown(%d)", id)
+	}
+	return c.Name()
+}
+
+// timestamp formats a modification time, or "-" when none was recorded.
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(timeLayout)
+}
+
+// owner returns the user or group name, or the numeric ID when no name was recorded.
+func owner(name string, id uint32) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprint(id)
+}
+
+// init registers the ListCmd flags during package initialization. The command
+// itself is added to the root command by cmds.InitCommands.
+func init() {
+	application.HandlePanic(func() {
+		ListCmd.Flags().BoolVarP(&longOutput, "long", "l", false, "also show mode, owner and group")
+		ListCmd.Flags().BoolVar(&jsonOutput, "json", false, "print entries as JSON")
+	})
+}
@@ -0,0 +1,110 @@
+package vlcList
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
+)
+
+// writeArchive packs the named contents into an archive file and returns its path.
+func writeArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	c, err := codec.Lookup("huffman")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	for _, name := range []string{"docs/readme.md", "main.go", "util/util.go"} {
+		w, err := aw.Create(container.Entry{Name: name, Mode: 0644, ModTime: time.Unix(1700000000, 0)})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive.vlc")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListFiltersByGlob(t *testing.T) {
+	path := writeArchive(t, map[string]string{
+		"docs/readme.md": "# Readme\n",
+		"main.go":        "package main\n",
+		"util/util.go":   "package util\n",
+	})
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{nil, []string{"docs/readme.md", "main.go", "util/util.go"}},
+		{[]string{"*.go"}, []string{"main.go", "util/util.go"}},
+		{[]string{"util/*"}, []string{"util/util.go"}},
+		{[]string{"*.md", "main.*"}, []string{"docs/readme.md", "main.go"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := list(&out, path, tt.patterns); err != nil {
+			t.Fatalf("list(%q) failed: %v", tt.patterns, err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != len(tt.want)+2 {
+			t.Fatalf("list(%q) printed %d lines, want header, %d entries and total:\n%s", tt.patterns, len(lines), len(tt.want), out.String())
+		}
+		for i, name := range tt.want {
+			if fields := strings.Fields(lines[i+1]); fields[len(fields)-1] != name {
+				t.Errorf("list(%q) line %d = %q, want entry %s", tt.patterns, i+1, lines[i+1], name)
+			}
+		}
+	}
+
+	if err := list(&bytes.Buffer{}, path, []string{"[bad"}); err == nil {
+		t.Error("list() with a malformed pattern succeeded, want error")
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	path := writeArchive(t, map[string]string{"main.go": strings.Repeat("package main\n", 100)})
+
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+
+	var out bytes.Buffer
+	if err := list(&out, path, []string{"main.go"}); err != nil {
+		t.Fatalf("list() failed: %v", err)
+	}
+
+	var entries []jsonEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Name != "main.go" || e.Type != "file" || e.Size != 1300 || e.Codec != "huffman" || e.Ratio <= 0 || e.Ratio >= 1 {
+		t.Errorf("entry = %+v, want compressed 1300-byte huffman file main.go", e)
+	}
+	if e.ModTime == nil || !e.ModTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("modTime = %v, want %v", e.ModTime, time.Unix(1700000000, 0))
+	}
+}
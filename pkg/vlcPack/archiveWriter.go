@@ -0,0 +1,171 @@
+package vlcPack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// ArchiveWriter packs several files into one archive. Like zip.Writer, each file
+// is added with Create and its contents written to the returned writer, and Close
+// writes the central directory that lists them. Entries are packed one after the
+// other, so only one can be written at a time. Every entry records the CRC32C
+// checksum of its contents; set SHA256 before the first Create to also record
+// SHA-256 digests.
+type ArchiveWriter struct {
+	SHA256 bool
+
+	w       *countingWriter
+	codec   codec.Codec
+	dir     container.Directory
+	names   map[string]bool
+	current *Writer
+	closed  bool
+	err     error
+}
+
+// NewArchiveWriter returns an ArchiveWriter packing every entry to w with the given codec.
+// It is the caller's responsibility to call Close on the ArchiveWriter when done.
+func NewArchiveWriter(w io.Writer, c codec.Codec) *ArchiveWriter {
+	return &ArchiveWriter{w: &countingWriter{w: w}, codec: c, names: make(map[string]bool)}
+}
+
+// Create finishes the previous entry and starts a new one described by e. Name,
+// Mode and ModTime are taken from e; Size, Codec, Offset and PackedSize are filled
+// in by the ArchiveWriter. The name must be a slash-separated relative path as
+// accepted by fs.ValidPath and must not repeat an earlier entry's. Directories
+// have no contents, and writing to the writer returned for them fails; a
+// symlink's contents are its target.
+func (aw *ArchiveWriter) Create(e container.Entry) (io.Writer, error) {
+	if aw.err != nil {
+		return nil, aw.err
+	}
+	if aw.closed {
+		return nil, errors.New("vlcPack: create in closed ArchiveWriter")
+	}
+	if !fs.ValidPath(e.Name) || e.Name == "." {
+		return nil, fmt.Errorf("invalid entry name %q", e.Name)
+	}
+	if aw.names[e.Name] {
+		return nil, fmt.Errorf("duplicate entry name %q", e.Name)
+	}
+
+	if aw.err = aw.finishEntry(); aw.err != nil {
+		return nil, aw.err
+	}
+	if aw.err = aw.writeHeader(); aw.err != nil {
+		return nil, aw.err
+	}
+
+	aw.names[e.Name] = true
+	e.Codec = aw.codec.ID()
+	e.Offset = uint64(aw.w.n)
+	aw.dir = append(aw.dir, e)
+	if e.Mode.IsDir() {
+		return dirWriter{name: e.Name}, nil
+	}
+	aw.current = newPayloadWriter(aw.w, aw.codec, aw.header())
+	return aw.current, nil
+}
+
+// Close finishes the last entry and writes the central directory and footer.
+// It does not close the underlying io.Writer.
+func (aw *ArchiveWriter) Close() error {
+	if aw.err != nil {
+		return aw.err
+	}
+	if aw.closed {
+		return nil
+	}
+	aw.closed = true
+
+	if aw.err = aw.finishEntry(); aw.err != nil {
+		return aw.err
+	}
+	if aw.err = aw.writeHeader(); aw.err != nil {
+		return aw.err
+	}
+
+	directory, err := aw.dir.MarshalBinary()
+	if err != nil {
+		aw.err = fmt.Errorf("marshal directory: %w", err)
+		return aw.err
+	}
+	footer, _ := container.Footer{DirectoryOffset: uint64(aw.w.n)}.MarshalBinary()
+	if _, aw.err = aw.w.Write(append(directory, footer...)); aw.err != nil {
+		return fmt.Errorf("write directory: %w", aw.err)
+	}
+	return nil
+}
+
+// writeHeader writes the archive header once, before the first entry.
+func (aw *ArchiveWriter) writeHeader() error {
+	if aw.w.n > 0 {
+		return nil
+	}
+
+	data, err := aw.header().MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshal header: %w", err)
+	}
+	if _, err := aw.w.Write(data); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	return nil
+}
+
+// header returns the archive header, which also describes every entry's payload.
+func (aw *ArchiveWriter) header() container.Header {
+	header := container.NewHeader(aw.codec.ID())
+	header.Flags |= container.FlagArchive
+	if aw.SHA256 {
+		header.Flags |= container.FlagSHA256
+	}
+	return header
+}
+
+// finishEntry closes the entry being written, if any, and records its sizes.
+func (aw *ArchiveWriter) finishEntry() error {
+	if aw.current == nil {
+		return nil
+	}
+	if err := aw.current.Close(); err != nil {
+		return err
+	}
+
+	e := &aw.dir[len(aw.dir)-1]
+	e.Size = aw.current.length
+	e.PackedSize = uint64(aw.w.n) - e.Offset
+	e.CRC32C = aw.current.trailer.CRC32C
+	e.SHA256 = aw.current.trailer.SHA256
+	aw.current = nil
+	return nil
+}
+
+// dirWriter is returned by Create for directories, which have no contents.
+type dirWriter struct {
+	name string
+}
+
+func (d dirWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("entry %q is a directory", d.name)
+}
+
+// countingWriter counts the bytes written through it, giving entry offsets.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,153 @@
+package vlcPack
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the file listing patterns to leave out of an archive.
+// It is read from every directory walked by AddPath, and its patterns apply to the
+// paths below that directory.
+const IgnoreFile = ".vlcignore"
+
+// rule is one glob pattern of a filter. Patterns without a slash match the base
+// name of a path at any depth; patterns with a slash match the whole path below
+// the directory the rule belongs to. A trailing slash restricts the rule to
+// directories, as in .gitignore.
+type rule struct {
+	base     string // slash-separated entry name of the directory the rule belongs to
+	pattern  string
+	anchored bool
+	dirOnly  bool
+}
+
+// parseRule parses one pattern relative to the directory named base. It returns
+// false for blank lines and # comments.
+func parseRule(base, line string) (rule, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false, nil
+	}
+
+	r := rule{base: base}
+	r.dirOnly = strings.HasSuffix(line, "/")
+	line = strings.TrimSuffix(line, "/")
+	r.anchored = strings.Contains(line, "/")
+	r.pattern = strings.TrimPrefix(line, "/")
+
+	if _, err := path.Match(r.pattern, ""); err != nil {
+		return rule{}, false, fmt.Errorf("pattern %q: %w", line, err)
+	}
+	return r, true, nil
+}
+
+// matches reports whether the rule applies to the entry name.
+func (r rule) matches(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	rel := name
+	if r.base != "" {
+		if !strings.HasPrefix(name, r.base+"/") {
+			return false
+		}
+		rel = name[len(r.base)+1:]
+	}
+	if !r.anchored {
+		rel = path.Base(rel)
+	}
+
+	ok, _ := path.Match(r.pattern, rel)
+	return ok
+}
+
+// filter decides which walked paths are packed, from the include and exclude
+// patterns of AddOptions and the IgnoreFile patterns met during the walk.
+type filter struct {
+	include []rule
+	exclude []rule
+}
+
+// newFilter parses the include and exclude patterns, which apply to paths below
+// the walked directory whose entry name is base, or to the base name of a single
+// file when base is empty.
+func newFilter(opts AddOptions, base string) (*filter, error) {
+	f := &filter{}
+	for _, patterns := range []struct {
+		lines []string
+		rules *[]rule
+	}{
+		{opts.Include, &f.include},
+		{opts.Exclude, &f.exclude},
+	} {
+		for _, line := range patterns.lines {
+			r, ok, err := parseRule(base, line)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				*patterns.rules = append(*patterns.rules, r)
+			}
+		}
+	}
+	return f, nil
+}
+
+// loadIgnoreFile adds the patterns of the IgnoreFile in dir, if there is one,
+// scoped to the directory's entry name.
+func (f *filter) loadIgnoreFile(dir, name string) error {
+	file, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open %s: %w", IgnoreFile, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		r, ok, err := parseRule(name, scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s in %s: %w", IgnoreFile, dir, err)
+		}
+		if ok {
+			f.exclude = append(f.exclude, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", IgnoreFile, err)
+	}
+	return nil
+}
+
+// excluded reports whether the entry name matches an exclude pattern.
+// Excluded directories are skipped with everything below them.
+func (f *filter) excluded(name string, isDir bool) bool {
+	for _, r := range f.exclude {
+		if r.matches(name, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// included reports whether a file or symlink is selected by the include
+// patterns. Without include patterns every file is.
+func (f *filter) included(name string) bool {
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, r := range f.include {
+		if r.matches(name, false) {
+			return true
+		}
+	}
+	return false
+}
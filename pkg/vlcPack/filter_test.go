@@ -0,0 +1,118 @@
+package vlcPack
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+)
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		base, pattern string
+		name          string
+		isDir         bool
+		want          bool
+	}{
+		{"", "*.log", "app.log", false, true},
+		{"", "*.log", "src/logs/app.log", false, true},
+		{"", "*.log", "app.log.txt", false, false},
+		{"", "build/", "src/build", true, true},
+		{"", "build/", "src/build", false, false},
+		{"", "src/*.go", "src/main.go", false, true},
+		{"", "/src/*.go", "src/main.go", false, true},
+		{"", "src/*.go", "lib/src/main.go", false, false},
+		{"project", "*.tmp", "project/a/b.tmp", false, true},
+		{"project", "*.tmp", "other/b.tmp", false, false},
+		{"project", "a/*.tmp", "project/a/b.tmp", false, true},
+		{"project/a", "b.tmp", "project/a/b.tmp", false, true},
+	}
+
+	for _, tt := range tests {
+		r, ok, err := parseRule(tt.base, tt.pattern)
+		if err != nil || !ok {
+			t.Fatalf("parseRule(%q, %q) = %v, %v", tt.base, tt.pattern, ok, err)
+		}
+		if got := r.matches(tt.name, tt.isDir); got != tt.want {
+			t.Errorf("rule %q in %q matches(%q, dir=%v) = %v, want %v", tt.pattern, tt.base, tt.name, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseRuleSkipsCommentsAndRejectsBadPatterns(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment"} {
+		if _, ok, err := parseRule("", line); ok || err != nil {
+			t.Errorf("parseRule(%q) = %v, %v, want skipped", line, ok, err)
+		}
+	}
+	if _, _, err := parseRule("", "[unclosed"); err == nil {
+		t.Error("parseRule(\"[unclosed\") succeeded, want error")
+	}
+}
+
+func TestAddPathMatchesPatternsBelowRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	for _, name := range []string{"src/main.go", "lib/src/util.go", "README.md"} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts AddOptions
+		want []string
+	}{
+		{
+			name: "anchored exclude",
+			opts: AddOptions{Exclude: []string{"src/*.go"}},
+			want: []string{"project/README.md", "project/lib/src/util.go"},
+		},
+		{
+			name: "anchored include",
+			opts: AddOptions{Include: []string{"/src/*.go"}},
+			want: []string{"project/src/main.go"},
+		},
+		{
+			name: "directory",
+			opts: AddOptions{Exclude: []string{"lib/"}},
+			want: []string{"project/README.md", "project/src/main.go"},
+		},
+		{
+			name: "base name",
+			opts: AddOptions{Exclude: []string{"*.go"}},
+			want: []string{"project/README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aw := NewArchiveWriter(io.Discard, c)
+			if err := aw.AddPath(root, tt.opts); err != nil {
+				t.Fatalf("AddPath() failed: %v", err)
+			}
+			var got []string
+			for _, e := range aw.dir {
+				if e.Mode.IsRegular() {
+					got = append(got, e.Name)
+				}
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("packed files = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
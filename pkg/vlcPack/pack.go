@@ -0,0 +1,208 @@
+package vlcPack
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/flexer2006/simpleArchiver-golang/internal/application"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/outputFile"
+	"github.com/spf13/cobra"
+)
+
+// packOptions holds the flags of PackCmd.
+type packOptions struct {
+	codecOptions
+
+	// add holds the --include, --exclude and --xattrs flags.
+	add AddOptions
+
+	// sha256 records a SHA-256 digest of every file alongside its CRC32C
+	// checksum. Set by the --sha256 flag.
+	sha256 bool
+
+	// toStdout writes the archive to standard output. Set by the --stdout flag.
+	toStdout bool
+
+	// output is the archive to write, "-" for standard output. Set by the
+	// --output flag; when empty the name comes from defaultArchivePath.
+	output string
+
+	// outputDir is the directory to write the archive to instead of the one
+	// holding the first path. Set by the --output-dir flag.
+	outputDir string
+
+	// overwrite says what happens to an existing archive. Set by the --force,
+	// --no-clobber and --suffix flags.
+	overwrite outputFile.Policy
+}
+
+// packOpts holds the flags given to PackCmd.
+var packOpts packOptions
+
+// PackCmd is the Cobra command for packing several files into one archive.
+// Usage: pack [path... | -]
+// Short: Pack files and directories into one archive.
+var PackCmd = &cobra.Command{
+	Use:   "pack [path... | -]",
+	Short: "Pack files and directories into one archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		application.HandlePanic(func() {
+			err := application.HandleError(func() error {
+				if len(args) == 0 {
+					path, err := application.InputPath(args)
+					if err != nil {
+						return err
+					}
+					args = []string{path}
+				}
+				// Like gzip, packing standard input writes to standard output unless
+				// an output file is named.
+				outputPath := packOpts.output
+				switch {
+				case packOpts.toStdout || (outputPath == "" && slices.Contains(args, application.StdioPath)):
+					outputPath = application.StdioPath
+				case outputPath == "":
+					var err error
+					if outputPath, err = defaultArchivePath(args[0], packOpts.outputDir); err != nil {
+						return err
+					}
+				}
+				return packArchive(outputPath, args, packOpts)
+			})
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		})
+	},
+}
+
+// packArchive packs the files and directory trees at the given paths into a new
+// archive at outputPath with the codec chosen by opts, honouring its --include
+// and --exclude patterns and IgnoreFile patterns. An existing archive is only
+// replaced as the --force, --no-clobber or --suffix policy allows. The path
+// application.StdioPath packs standard input as an entry named stdinEntryName;
+// as outputPath it writes the archive to standard output. Returns an error if
+// any step fails.
+func packArchive(outputPath string, paths []string, opts packOptions) error {
+	c, err := opts.selectCodec()
+	if err != nil {
+		return err
+	}
+
+	if outputPath == application.StdioPath {
+		if application.IsTerminal(os.Stdout) {
+			return application.ErrTerminalOutput
+		}
+		_, err := writeArchive(os.Stdout, c, paths, opts)
+		return err
+	}
+
+	output, err := outputFile.Create(outputPath, 0644, opts.overwrite)
+	if errors.Is(err, outputFile.ErrSkipped) {
+		log.Printf("Warning: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer output.Abort()
+
+	if opts.add.Skip, err = output.Stat(); err != nil {
+		return fmt.Errorf("stat output file: %w", err)
+	}
+
+	count, err := writeArchive(output, c, paths, opts)
+	if err != nil {
+		return err
+	}
+	if err := output.Commit(); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+
+	log.Printf("%d entries successfully packed: %s", count, output.Name())
+	return nil
+}
+
+// defaultArchivePath returns the archive PackCmd writes when --output is not
+// given: the name of the first path with its extension replaced by `.vlc`, next
+// to it or in dir if dir is not empty. A first path such as "." or ".." is named
+// after the directory it resolves to. Returns an error if that is the root.
+func defaultArchivePath(first, dir string) (string, error) {
+	path := filepath.Clean(first)
+	if base := filepath.Base(path); base == "." || base == ".." {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("resolve %s: %w", first, err)
+		}
+		path = abs
+	}
+	if filepath.Dir(path) == path {
+		return "", fmt.Errorf("cannot name an archive after %s; use --output", first)
+	}
+
+	archive := generateOutputPath(path)
+	if dir != "" {
+		archive = filepath.Join(dir, filepath.Base(archive))
+	}
+	return archive, nil
+}
+
+// writeArchive writes an archive of the given paths to w with the codec c and the
+// options in opts, and returns the number of entries packed.
+func writeArchive(w io.Writer, c codec.Codec, paths []string, opts packOptions) (int, error) {
+	buffered := bufio.NewWriter(w)
+	aw := NewArchiveWriter(buffered, c)
+	aw.SHA256 = opts.sha256
+	for _, path := range paths {
+		var err error
+		if path == application.StdioPath {
+			err = aw.addReader(stdinEntryName, os.Stdin)
+		} else {
+			err = aw.AddPath(path, opts.add)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("pack %s: %w", path, err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		return 0, fmt.Errorf("pack: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("write output file: %w", err)
+	}
+	return len(aw.dir), nil
+}
+
+// init registers the PackCmd flags during package initialization. The command
+// itself is added to the root command by cmds.InitCommands.
+func init() {
+	application.HandlePanic(func() {
+		flags := PackCmd.Flags()
+		flags.StringVarP(&packOpts.output, "output", "o", "",
+			"path of the archive to write, - for standard output (default: named after the first path)")
+		flags.StringVar(&packOpts.outputDir, "output-dir", "",
+			"directory to write the archive to (default: the first path's directory)")
+		flags.StringVar(&packOpts.name, "codec", codec.Default,
+			"codec to encode with: "+codecNames())
+		flags.IntVar(&packOpts.windowBits, "window", 0, windowUsage)
+		flags.StringArrayVar(&packOpts.add.Include, "include", nil,
+			"only pack files whose paths match this glob (repeatable)")
+		flags.StringArrayVar(&packOpts.add.Exclude, "exclude", nil,
+			"leave out paths matching this glob (repeatable)")
+		flags.BoolVar(&packOpts.add.Xattrs, "xattrs", false,
+			"record extended attributes")
+		flags.BoolVar(&packOpts.sha256, "sha256", false,
+			"also record a SHA-256 digest of every file")
+		flags.BoolVarP(&packOpts.toStdout, "stdout", "c", false,
+			"write the archive to standard output")
+		PackCmd.MarkFlagsMutuallyExclusive("stdout", "output", "output-dir")
+		packOpts.overwrite.AddFlags(PackCmd)
+	})
+}
@@ -0,0 +1,44 @@
+package vlcPack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultArchivePath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		first string
+		dir   string
+		want  string
+	}{
+		{name: "file", first: filepath.Join("docs", "notes.txt"), want: filepath.Join("docs", "notes.vlc")},
+		{name: "directory", first: filepath.Join("src", "project"), want: filepath.Join("src", "project.vlc")},
+		{name: "trailing separator", first: "project" + string(filepath.Separator), want: "project.vlc"},
+		{name: "output dir", first: filepath.Join("src", "project"), dir: "out", want: filepath.Join("out", "project.vlc")},
+		{name: "current directory", first: ".", want: filepath.Join(filepath.Dir(wd), filepath.Base(wd)+".vlc")},
+		{name: "current directory to output dir", first: ".", dir: "out", want: filepath.Join("out", filepath.Base(wd)+".vlc")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := defaultArchivePath(tt.first, tt.dir)
+			if err != nil {
+				t.Fatalf("defaultArchivePath(%q, %q) failed: %v", tt.first, tt.dir, err)
+			}
+			if got != tt.want {
+				t.Errorf("defaultArchivePath(%q, %q) = %q, want %q", tt.first, tt.dir, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := defaultArchivePath(string(filepath.Separator), ""); err == nil {
+		t.Error("defaultArchivePath() of the root succeeded, want error")
+	}
+}
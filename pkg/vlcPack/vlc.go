@@ -1,8 +1,13 @@
-// Package vlcPack provides functionality for packing files using variable-length code (VLC) encoding.
-// It includes a CLI command to encode a file and save the result with a `.vlc` extension.
+// Package vlcPack provides functionality for packing files using variable-length
+// code (VLC) encoding. It includes a CLI command to encode a file and save the
+// result with a `.vlc` extension, and one to pack several files into a single
+// archive. Both can read standard input and write standard output, so they fit
+// in shell pipelines.
 package vlcPack
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -11,6 +16,11 @@ import (
 	"strings"
 
 	"github.com/flexer2006/simpleArchiver-golang/internal/application"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/lz77"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/outputFile"
 	"github.com/spf13/cobra"
 )
 
@@ -19,11 +29,47 @@ const (
 	packedExtension = "vlc"
 )
 
+// windowUsage is the help text of the --window flag.
+var windowUsage = fmt.Sprintf("lz77 window size as a power of two, %d to %d (default %d)",
+	lz77.MinWindowBits, lz77.MaxWindowBits, lz77.DefaultWindowBits)
+
+// vlcPackOptions holds the flags of VlcPackCmd.
+type vlcPackOptions struct {
+	codecOptions
+
+	// text selects the space-separated hex output mode instead of raw bytes. Set
+	// by the --text flag; intended for debugging only.
+	text bool
+
+	// sha256 records SHA-256 digests alongside the CRC32C checksums. Set by the
+	// --sha256 flag.
+	sha256 bool
+
+	// toStdout writes the packed data to standard output instead of a file. Set by
+	// the --stdout flag.
+	toStdout bool
+
+	// output is the file to write, "-" for standard output. Set by the --output
+	// flag; when empty the name comes from generateOutputPath.
+	output string
+
+	// outputDir is the directory to write to instead of the input's directory.
+	// Set by the --output-dir flag.
+	outputDir string
+
+	// overwrite says what happens to existing output files. Set by the --force,
+	// --no-clobber and --suffix flags.
+	overwrite outputFile.Policy
+}
+
+// vlcPackOpts holds the flags given to VlcPackCmd.
+var vlcPackOpts vlcPackOptions
+
 // VlcPackCmd is the Cobra command for packing files using variable-length code.
-// Usage: vlcPack [file_path]
+// Usage: vlcPack [file_path | -]
 // Short: Pack file using variable-length code.
 var VlcPackCmd = &cobra.Command{
-	Use:   "vlcPack [file_path]",
+	Use:   "vlcPack [file_path | -]",
 	Short: "Pack file using variable-length code",
 	Run: func(cmd *cobra.Command, args []string) {
 		application.HandlePanic(func() {
@@ -34,60 +80,167 @@ var VlcPackCmd = &cobra.Command{
 	},
 }
 
-// validateAndPack validates the input arguments and initiates the packing process.
-// Returns an error if the file path is empty or if packing fails.
+// validateAndPack validates the input arguments and initiates the packing
+// process. Returns an error if no input is given or if packing fails.
 func validateAndPack(args []string) error {
 	return application.HandleError(func() error {
-		if len(args) == 0 || args[0] == "" {
-			return application.ErrEmptyPath
+		filePath, err := application.InputPath(args)
+		if err != nil {
+			return err
 		}
-		return pack(args[0])
+		return pack(filePath, vlcPackOpts)
 	})
 }
 
-// pack reads the file at the given path, encodes its contents using variable-length code,
-// and writes the encoded data to a new file with a `.vlc` extension.
-// Returns an error if any step fails.
-func pack(filePath string) error {
-	file, err := os.Open(filePath)
+// pack streams the file at the given path through a Writer using the registered
+// codec chosen by opts, recording the file's name in the header, and writes the
+// packed bytes to the file chosen by packedPath, following the --force,
+// --no-clobber or --suffix policy if it exists. The path application.StdioPath
+// reads standard input. With --text the packed bytes are written as
+// space-separated hex chunks. Returns an error if any step fails.
+func pack(filePath string, opts vlcPackOptions) error {
+	c, err := opts.selectCodec()
 	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+		return err
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			log.Printf("Warning: failed to close file: %v", closeErr)
+
+	var input io.Reader = os.Stdin
+	var info os.FileInfo
+	if filePath != application.StdioPath {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("open file: %w", err)
 		}
-	}()
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil {
+				log.Printf("Warning: failed to close file: %v", closeErr)
+			}
+		}()
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return fmt.Errorf("read file: %w", err)
+		if info, err = file.Stat(); err != nil {
+			return fmt.Errorf("stat file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory; use pack to archive directories", filePath)
+		}
+		input = file
 	}
 
-	encoded, err := Encode(string(data))
+	var name string
+	if info != nil {
+		if name = info.Name(); !container.ValidName(name) {
+			log.Printf("Warning: not recording file name %q: too long or unsupported characters", name)
+			name = ""
+		}
+	}
+
+	target := packedPath(filePath, opts)
+	if target == application.StdioPath {
+		return packToStdout(input, name, c, opts)
+	}
+	if info != nil && outputFile.IsInput(target, info) {
+		return fmt.Errorf("output file %s is the input file", target)
+	}
+
+	output, err := outputFile.Create(target, 0644, opts.overwrite)
+	if errors.Is(err, outputFile.ErrSkipped) {
+		log.Printf("Warning: %v", err)
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("encode: %w", err)
+		return fmt.Errorf("create output file: %w", err)
 	}
+	defer output.Abort()
 
-	outputPath := generateOutputPath(filePath)
-	if err := os.WriteFile(outputPath, []byte(encoded), 0644); err != nil {
+	if err := packStream(output, input, name, c, opts); err != nil {
+		return err
+	}
+	if err := output.Commit(); err != nil {
 		return fmt.Errorf("write output file: %w", err)
 	}
 
-	log.Printf("File successfully packed: %s", outputPath)
+	log.Printf("File successfully packed: %s", output.Name())
+	return nil
+}
+
+// packedPath returns where pack writes the packed form of filePath: standard
+// output for --stdout or standard input, the --output path, or the name from
+// generateOutputPath in --output-dir or, by default, next to the input file.
+func packedPath(filePath string, opts vlcPackOptions) string {
+	switch {
+	case opts.toStdout:
+		return application.StdioPath
+	case opts.output != "":
+		return opts.output
+	case filePath == application.StdioPath:
+		return application.StdioPath
+	case opts.outputDir != "":
+		return filepath.Join(opts.outputDir, filepath.Base(generateOutputPath(filePath)))
+	default:
+		return generateOutputPath(filePath)
+	}
+}
+
+// packToStdout packs everything read from src to standard output with the codec
+// c. Raw packed bytes are not written to a terminal.
+func packToStdout(src io.Reader, name string, c codec.Codec, opts vlcPackOptions) error {
+	if !opts.text && application.IsTerminal(os.Stdout) {
+		return application.ErrTerminalOutput
+	}
+	return packStream(os.Stdout, src, name, c, opts)
+}
+
+// packStream packs everything read from src into dst with the codec c, honouring
+// --text. A non-empty name is recorded in the header as the original file name.
+func packStream(dst io.Writer, src io.Reader, name string, c codec.Codec, opts vlcPackOptions) error {
+	buffered := bufio.NewWriter(dst)
+	var out io.Writer = buffered
+	if opts.text {
+		out = chunks.NewHexWriter(buffered)
+	}
+
+	zw := NewWriterCodec(out, c)
+	zw.Header.Name = name
+	if opts.sha256 {
+		zw.Header.Flags |= container.FlagSHA256
+	}
+	if _, err := io.Copy(zw, src); err != nil {
+		return fmt.Errorf("pack: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("pack: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
 	return nil
 }
 
-// generateOutputPath generates the output file path by replacing the original file's
-// extension with `.vlc`.
+// generateOutputPath generates the output file path by replacing the original
+// file's extension with `.vlc`, keeping its directory.
 func generateOutputPath(path string) string {
-	base := filepath.Base(path)
-	return strings.TrimSuffix(base, filepath.Ext(base)) + "." + packedExtension
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + packedExtension
 }
 
-// init registers the VlcPackCmd with the root command during package initialization.
+// init registers the VlcPackCmd flags during package initialization. The command
+// itself is added to the root command by cmds.InitCommands.
 func init() {
 	application.HandlePanic(func() {
-		application.RootCmd.AddCommand(VlcPackCmd)
+		flags := VlcPackCmd.Flags()
+		flags.BoolVar(&vlcPackOpts.text, "text", false,
+			"write packed data as space-separated hex (debugging)")
+		flags.StringVar(&vlcPackOpts.name, "codec", codec.Default,
+			"codec to encode with: "+codecNames())
+		flags.IntVar(&vlcPackOpts.windowBits, "window", 0, windowUsage)
+		flags.BoolVar(&vlcPackOpts.sha256, "sha256", false,
+			"also record a SHA-256 digest of the data")
+		flags.BoolVarP(&vlcPackOpts.toStdout, "stdout", "c", false,
+			"write packed data to standard output")
+		flags.StringVarP(&vlcPackOpts.output, "output", "o", "",
+			"path of the packed file, - for standard output")
+		flags.StringVar(&vlcPackOpts.outputDir, "output-dir", "",
+			"directory to write the packed file to (default: the input's directory)")
+		VlcPackCmd.MarkFlagsMutuallyExclusive("stdout", "output", "output-dir")
+		vlcPackOpts.overwrite.AddFlags(VlcPackCmd)
 	})
 }
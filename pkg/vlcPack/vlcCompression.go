@@ -1,94 +1,101 @@
-// Package vlcPack provides functionality for encoding text into binary format
-// using variable-length codes (VLC). It prepares the text, encodes it into binary,
-// and converts the binary data into hexadecimal chunks for storage or transmission.
+// Package vlcPack provides functionality for encoding data into the `.vlc` format.
+// The payload is produced block by block by one of the registered codecs (see the
+// codec package) and preceded by a container.Header naming the codec. Encode and
+// EncodeWith are in-memory conveniences built on Writer.
 package vlcPack
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
-	"unicode"
 
 	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
-	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
 )
 
-// Encode takes a string, prepares it for encoding, converts it to binary using
-// a predefined encoding table, and returns the result as a space-separated
-// hexadecimal string.
+// Encode takes a string, encodes it with the default fixed-table VLC codec and
+// returns the packed `.vlc` stream.
 //
 // Parameters:
 //   - str: The input string to encode.
 //
 // Returns:
-//   - string: The encoded hexadecimal string.
-//   - error: An error if encoding fails (e.g., due to an undefined character).
-func Encode(str string) (string, error) {
-	// Prepare the text by handling uppercase letters
-	prepared := prepareText(str)
-
-	// Encode the prepared text into binary
-	encoded, err := encodeToBinary(prepared)
-	if err != nil {
-		return "", fmt.Errorf("encode: %w", err)
-	}
-
-	// Split the binary string into chunks and convert to hexadecimal
-	binaryChunks, err := chunks.SplitByChunks(encoded)
+//   - []byte: The packed `.vlc` stream.
+//   - error: An error if encoding fails.
+func Encode(str string) ([]byte, error) {
+	c, err := codec.Lookup(codec.Default)
 	if err != nil {
-		return "", fmt.Errorf("split binary into chunks: %w", err)
+		return nil, err
 	}
-
-	hexChunks, err := binaryChunks.ToHex()
-	if err != nil {
-		return "", fmt.Errorf("convert binary chunks to hex: %w", err)
-	}
-
-	// Join the hexadecimal chunks into a space-separated string
-	return hexChunks.ToString(), nil
+	return EncodeWith(c, []byte(str))
 }
 
-// prepareText processes the input string to handle uppercase letters.
-// Uppercase letters are prefixed with '!' and converted to lowercase.
+// EncodeWith encodes data with the given codec and returns the complete `.vlc`
+// stream: the container.Header, the encoded blocks and the trailer.
 //
 // Parameters:
-//   - str: The input string to prepare.
+//   - c: The codec producing the payload.
+//   - data: The bytes to encode.
 //
 // Returns:
-//   - string: The processed string with uppercase letters handled.
-func prepareText(str string) string {
-	var buf strings.Builder
-	for _, r := range str {
-		if unicode.IsUpper(r) {
-			buf.WriteRune('!')
-			buf.WriteRune(unicode.ToLower(r))
-		} else {
-			buf.WriteRune(r)
-		}
+//   - []byte: The packed `.vlc` stream.
+//   - error: An error if encoding fails.
+func EncodeWith(c codec.Codec, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := NewWriterCodec(&buf, c)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
 	}
-	return buf.String()
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
 
-// encodeToBinary converts a string into a binary string using a predefined
-// encoding table. Each character in the string is replaced with its corresponding
-// binary code from the table.
+// EncodeText encodes a string like Encode but returns the packed data as a
+// space-separated hexadecimal string (e.g., "A1 FF"). It is intended for
+// debugging, since the text form takes three bytes per packed byte.
 //
 // Parameters:
 //   - str: The input string to encode.
 //
 // Returns:
-//   - string: The binary-encoded string.
-//   - error: An error if a character in the string is not found in the encoding table.
-func encodeToBinary(str string) (string, error) {
-	table := table.BuildEncodingTable()
-	var builder strings.Builder
+//   - string: The encoded hexadecimal string.
+//   - error: An error if encoding fails.
+func EncodeText(str string) (string, error) {
+	packed, err := Encode(str)
+	if err != nil {
+		return "", err
+	}
 
-	for _, r := range str {
-		code, ok := table[r]
-		if !ok {
-			return "", fmt.Errorf("undefined character: %U", r)
-		}
-		builder.WriteString(code)
+	return chunks.NewHexChunksFromBytes(packed).ToString(), nil
+}
+
+// codecOptions holds the --codec and --window flags of a command.
+type codecOptions struct {
+	name       string // registered codec used for the payload
+	windowBits int    // lz77 window as a power of two, or zero for its default
+}
+
+// selectCodec returns the codec chosen by --codec, with the window size chosen by
+// --window for the lz77 codec. Returns an error for an unknown codec, an invalid
+// window size, or --window with a codec that has no window.
+func (o codecOptions) selectCodec() (codec.Codec, error) {
+	c, err := codec.Lookup(o.name)
+	if err != nil {
+		return nil, fmt.Errorf("%w (available: %s)", err, codecNames())
+	}
+	if o.windowBits == 0 {
+		return c, nil
 	}
+	if c.ID() != codec.IDLZ77 {
+		return nil, fmt.Errorf("--window does not apply to the %s codec", c.Name())
+	}
+	return codec.NewLZ77(o.windowBits)
+}
 
-	return builder.String(), nil
+// codecNames returns the registered codec names for use in help and error messages.
+func codecNames() string {
+	return strings.Join(codec.Names(), ", ")
 }
@@ -1 +1,100 @@
 package vlcPack_test
+
+import (
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcUnpack"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "trailing e padding", input: "e"},
+		{name: "sentence", input: "Hello, world! 42"},
+		{name: "literal bang", input: "wow! it works"},
+		{name: "bang before letter", input: "wow!a !B !!c"},
+		{name: "caps run", input: "HELLO WORLD 2024, Then back"},
+		{name: "mixed case", input: "iPhone McDonald's ABc aBC"},
+		{name: "source text", input: "func main() {\n\tprintln(\"a/b: 'c';\")\n}\n"},
+		{name: "non-ascii", input: "Привет, мир! Ünïcødé İstanbul"},
+		{name: "invalid utf-8", input: "\xff\xfeabc\xc3"},
+		{name: "all bytes", input: allBytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed, err := vlcPack.Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			decoded, err := vlcUnpack.Decode(packed)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if decoded != tt.input {
+				t.Errorf("Decode(Encode(%q)) = %q", tt.input, decoded)
+			}
+		})
+	}
+}
+
+func TestEncodeWithEveryCodec(t *testing.T) {
+	input := "Every codec round-trips: aaaaaaaaaaaaaaaa\x00\xff"
+
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			c, err := codec.Lookup(name)
+			if err != nil {
+				t.Fatalf("Lookup() failed: %v", err)
+			}
+
+			packed, err := vlcPack.EncodeWith(c, []byte(input))
+			if err != nil {
+				t.Fatalf("EncodeWith() failed: %v", err)
+			}
+
+			decoded, err := vlcUnpack.Decode(packed)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if decoded != input {
+				t.Errorf("Decode(EncodeWith(%q)) = %q", input, decoded)
+			}
+		})
+	}
+}
+
+func allBytes() string {
+	buf := make([]byte, 256)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	return string(buf)
+}
+
+func FuzzEncodeDecode(f *testing.F) {
+	for _, seed := range []string{"", "wow!a", "ABC def", "Ünïcødé İ", "\xff\x00!"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		packed, err := vlcPack.Encode(input)
+		if err != nil {
+			t.Fatalf("Encode() failed: %v", err)
+		}
+
+		decoded, err := vlcUnpack.Decode(packed)
+		if err != nil {
+			t.Fatalf("Decode() failed: %v", err)
+		}
+		if decoded != input {
+			t.Errorf("Decode(Encode(%q)) = %q", input, decoded)
+		}
+	})
+}
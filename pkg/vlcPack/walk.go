@@ -0,0 +1,177 @@
+package vlcPack
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/fileMetadata"
+)
+
+// stdinEntryName is the name of the entry holding data packed from standard input.
+const stdinEntryName = "stdin"
+
+// AddOptions controls which files AddPath packs.
+type AddOptions struct {
+	// Include, when not empty, limits the files and symlinks packed to those
+	// whose paths relative to the walked directory match one of these glob
+	// patterns. Directories are always walked.
+	Include []string
+
+	// Exclude lists glob patterns of paths relative to the walked directory to
+	// leave out. An excluded directory is skipped with everything below it.
+	Exclude []string
+
+	// Skip, if set, is a file never packed, such as the archive being written.
+	Skip os.FileInfo
+
+	// Xattrs records the extended attributes of every file.
+	Xattrs bool
+}
+
+// AddPath adds the file, symlink or directory tree at root to the archive. A
+// directory is walked recursively and stored under its base name with every
+// directory, regular file and symlink below it, using slash-separated paths
+// relative to its parent. Symlinks are stored, not followed: the link target is
+// the content of their entry. Patterns from opts and from IgnoreFile files in the
+// walked directories select what is packed; see AddOptions. Every entry records
+// the file's mode, modification and access times, and owner (see fileMetadata).
+func (aw *ArchiveWriter) AddPath(root string, opts AddOptions) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+	prefix, err := rootName(root)
+	if err != nil {
+		return err
+	}
+	base := ""
+	if info.IsDir() {
+		base = prefix
+	}
+	f, err := newFilter(opts, base)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if f.excluded(prefix, false) || !f.included(prefix) {
+			return nil
+		}
+		return aw.addEntry(root, prefix, info, opts)
+	}
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+
+		if f.excluded(name, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := f.loadIgnoreFile(p, name); err != nil {
+				return err
+			}
+		} else if !f.included(name) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if opts.Skip != nil && os.SameFile(info, opts.Skip) {
+			return nil
+		}
+		return aw.addEntry(p, name, info, opts)
+	})
+}
+
+// rootName returns the entry name for a path given to AddPath: its base name.
+func rootName(root string) (string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(abs)
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("cannot name an entry after %q", root)
+	}
+	return name, nil
+}
+
+// addEntry adds the file at p, described by info, as the entry name. Files other
+// than directories, regular files and symlinks are skipped with a warning.
+func (aw *ArchiveWriter) addEntry(p, name string, info fs.FileInfo, opts AddOptions) error {
+	mode := info.Mode()
+	if !mode.IsDir() && !mode.IsRegular() && mode&fs.ModeSymlink == 0 {
+		log.Printf("Warning: skipping %s: unsupported file type %v", p, mode.Type())
+		return nil
+	}
+
+	e := container.Entry{Name: name, Mode: mode, ModTime: info.ModTime()}
+	if err := fileMetadata.Read(&e, p, info, opts.Xattrs); err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	w, err := aw.Create(e)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case mode.IsDir():
+		return nil
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(p)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, target)
+		return err
+	default:
+		return copyFile(w, p)
+	}
+}
+
+// addReader adds everything read from r as a regular file entry called name,
+// stamped with the current time since r has no metadata of its own.
+func (aw *ArchiveWriter) addReader(name string, r io.Reader) error {
+	w, err := aw.Create(container.Entry{Name: name, Mode: 0644, ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// copyFile writes the contents of the file at p to w.
+func copyFile(w io.Writer, p string) error {
+	file, err := os.Open(p)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("Warning: failed to close file: %v", closeErr)
+		}
+	}()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("pack %s: %w", p, err)
+	}
+	return nil
+}
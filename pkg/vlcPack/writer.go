@@ -0,0 +1,191 @@
+package vlcPack
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// Writer is an io.WriteCloser that packs everything written to it into the `.vlc`
+// format. Input is buffered into blocks of container.BlockSize bytes, and each
+// full block is encoded and written out, so memory use does not grow with the
+// size of the input. Like gzip.Writer, the Header may be adjusted before the
+// first call to Write or Close, for example to set container.FlagSHA256, and
+// Close must be called to finish the stream.
+type Writer struct {
+	Header container.Header
+
+	w           io.Writer
+	codec       codec.Codec
+	buf         []byte
+	packed      bytes.Buffer
+	length      uint64
+	crc         hash.Hash32
+	digest      hash.Hash // nil unless the header has container.FlagSHA256
+	trailer     container.Trailer
+	wroteHeader bool
+	closed      bool
+	err         error
+}
+
+// NewWriter returns a Writer packing to w with the default codec.
+// It is the caller's responsibility to call Close on the Writer when done.
+func NewWriter(w io.Writer) *Writer {
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		// The default codec is registered by the codec package itself.
+		panic(err)
+	}
+	return NewWriterCodec(w, c)
+}
+
+// NewWriterCodec returns a Writer packing to w with the given codec.
+// It is the caller's responsibility to call Close on the Writer when done.
+func NewWriterCodec(w io.Writer, c codec.Codec) *Writer {
+	return &Writer{
+		Header: container.NewHeader(c.ID()),
+		w:      w,
+		codec:  c,
+		buf:    make([]byte, 0, container.BlockSize),
+		crc:    container.NewChecksum(),
+	}
+}
+
+// newPayloadWriter returns a Writer that packs to w with the given codec but
+// writes no header, for the entries of an archive whose header is given.
+func newPayloadWriter(w io.Writer, c codec.Codec, header container.Header) *Writer {
+	z := NewWriterCodec(w, c)
+	z.Header = header
+	z.startPayload()
+	return z
+}
+
+// Write buffers p and packs every block that fills up. It returns len(p) unless
+// packing or writing a block fails.
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.closed {
+		return 0, errors.New("vlcPack: write to closed Writer")
+	}
+
+	n := 0
+	for len(p) > 0 {
+		chunk := min(len(p), container.BlockSize-len(z.buf))
+		z.buf = append(z.buf, p[:chunk]...)
+		p = p[chunk:]
+		n += chunk
+
+		if len(z.buf) == container.BlockSize {
+			if z.err = z.flushBlock(); z.err != nil {
+				return n - chunk, z.err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close packs any buffered data and writes the end-of-payload marker and the
+// trailer. It does not close the underlying io.Writer.
+func (z *Writer) Close() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.closed {
+		return nil
+	}
+	z.closed = true
+
+	if len(z.buf) > 0 {
+		if z.err = z.flushBlock(); z.err != nil {
+			return z.err
+		}
+	}
+	if z.err = z.writeHeader(); z.err != nil {
+		return z.err
+	}
+
+	z.trailer = container.Trailer{Length: z.length, CRC32C: z.crc.Sum32()}
+	if z.digest != nil {
+		z.trailer.SHA256 = z.digest.Sum(nil)
+	}
+	end, _ := container.BlockHeader{}.MarshalBinary()
+	trailer, err := z.trailer.MarshalBinary()
+	if err != nil {
+		z.err = err
+		return fmt.Errorf("marshal trailer: %w", err)
+	}
+	if _, z.err = z.w.Write(append(end, trailer...)); z.err != nil {
+		return fmt.Errorf("write trailer: %w", z.err)
+	}
+	return nil
+}
+
+// startPayload fixes the header, which may no longer change, and prepares the
+// checksums it asks for.
+func (z *Writer) startPayload() {
+	z.wroteHeader = true
+	if z.Header.Flags&container.FlagSHA256 != 0 {
+		z.digest = sha256.New()
+	}
+}
+
+// writeHeader writes the container header once, before the first block.
+func (z *Writer) writeHeader() error {
+	if z.wroteHeader {
+		return nil
+	}
+	z.startPayload()
+
+	header, err := z.Header.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshal header: %w", err)
+	}
+	if _, err := z.w.Write(header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	return nil
+}
+
+// flushBlock encodes the buffered data as one block and writes it out.
+func (z *Writer) flushBlock() error {
+	if err := z.writeHeader(); err != nil {
+		return err
+	}
+
+	z.packed.Reset()
+	if err := z.codec.Encode(&z.packed, bytes.NewReader(z.buf)); err != nil {
+		return fmt.Errorf("%s encode: %w", z.codec.Name(), err)
+	}
+
+	z.crc.Write(z.buf)
+	if z.digest != nil {
+		z.digest.Write(z.buf)
+	}
+
+	blockHeader, err := container.BlockHeader{
+		RawLength:    uint64(len(z.buf)),
+		PackedLength: uint64(z.packed.Len()),
+		CRC32C:       container.Checksum(z.buf),
+	}.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshal block header: %w", err)
+	}
+	if _, err := z.w.Write(blockHeader); err != nil {
+		return fmt.Errorf("write block header: %w", err)
+	}
+	if _, err := z.w.Write(z.packed.Bytes()); err != nil {
+		return fmt.Errorf("write block: %w", err)
+	}
+
+	z.length += uint64(len(z.buf))
+	z.buf = z.buf[:0]
+	return nil
+}
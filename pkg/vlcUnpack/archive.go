@@ -0,0 +1,91 @@
+package vlcUnpack
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/flexer2006/simpleArchiver-golang/internal/application"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// singleFileMode is the mode reported for the entry of a single-file stream,
+// which records no metadata of its own.
+const singleFileMode = 0644
+
+// Archive gives access to the entries of a `.vlc` archive. A single-file stream,
+// as written by Writer, is presented as an archive of one entry named after the
+// original file recorded in the header, or with an empty Name if there is none,
+// the original length as Size and no modification time.
+type Archive struct {
+	Header  container.Header
+	Entries container.Directory
+
+	r io.ReaderAt
+}
+
+// OpenArchive reads the header and central directory of the archive of the
+// given size held by r.
+func OpenArchive(r io.ReaderAt, size int64) (*Archive, error) {
+	header, err := container.ReadHeader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{Header: header, r: r}
+	if header.Flags&container.FlagArchive != 0 {
+		if a.Entries, err = container.ReadDirectory(r, size); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+
+	// A single-file stream: the payload runs to the end and finishes with the trailer.
+	headerSize := int64(header.Size())
+	trailerSize := int64(header.TrailerSize())
+	if size < headerSize+trailerSize {
+		return nil, fmt.Errorf("%w: missing trailer", container.ErrTruncated)
+	}
+	trailer, err := container.ReadTrailer(io.NewSectionReader(r, size-trailerSize, trailerSize), header)
+	if err != nil {
+		return nil, err
+	}
+	a.Entries = container.Directory{{
+		Name:       header.Name,
+		Size:       trailer.Length,
+		Mode:       singleFileMode,
+		Codec:      header.Codec,
+		Offset:     uint64(headerSize),
+		PackedSize: uint64(size - headerSize),
+		CRC32C:     trailer.CRC32C,
+		SHA256:     trailer.SHA256,
+	}}
+	return a, nil
+}
+
+// SingleFileName returns the name used for the unnamed entry of a single-file
+// stream at archivePath, written without the original file name: the base name
+// of the file vlcUnpack writes it to, or "stdin.txt" for a stream read from
+// standard input.
+func SingleFileName(archivePath string) string {
+	if archivePath == application.StdioPath {
+		archivePath = "stdin"
+	}
+	return filepath.Base(generateOutputPath(archivePath))
+}
+
+// Open returns a Reader yielding the unpacked contents of the entry e: the file
+// contents, or the link target of a symlink. Besides the checks of every Reader,
+// the contents must match the size and checksums recorded in e. Directories
+// cannot be opened.
+func (a *Archive) Open(e container.Entry) (*Reader, error) {
+	if e.Mode.IsDir() {
+		return nil, fmt.Errorf("entry %q is a directory", e.Name)
+	}
+	c, err := codec.ByID(e.Codec)
+	if err != nil {
+		return nil, fmt.Errorf("entry %q: %w", e.Name, err)
+	}
+	return newPayloadReader(io.NewSectionReader(a.r, int64(e.Offset), int64(e.PackedSize)), c, a.Header, e), nil
+}
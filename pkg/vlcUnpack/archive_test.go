@@ -0,0 +1,156 @@
+package vlcUnpack
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	c, err := codec.Lookup("huffman")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	modTime := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	files := []struct {
+		name    string
+		mode    fs.FileMode
+		content []byte
+	}{
+		{"readme.txt", 0644, []byte("An archive of several files.\n")},
+		{"empty", 0600, nil},
+		{"big.txt", 0755, bytes.Repeat([]byte("Blocks span entries. "), container.BlockSize/10)},
+	}
+
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	for _, f := range files {
+		w, err := aw.Create(container.Entry{Name: f.name, Mode: f.mode, ModTime: modTime})
+		if err != nil {
+			t.Fatalf("Create(%q) failed: %v", f.name, err)
+		}
+		if _, err := w.Write(f.content); err != nil {
+			t.Fatalf("Write(%q) failed: %v", f.name, err)
+		}
+	}
+	if _, err := aw.Create(container.Entry{Name: "empty"}); err == nil {
+		t.Error("Create() with a duplicate name succeeded, want error")
+	}
+	if _, err := aw.Create(container.Entry{Name: "../escape"}); err == nil {
+		t.Error("Create() with a name outside the archive succeeded, want error")
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	archive, err := OpenArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenArchive() failed: %v", err)
+	}
+	if len(archive.Entries) != len(files) {
+		t.Fatalf("OpenArchive() found %d entries, want %d", len(archive.Entries), len(files))
+	}
+	for i, e := range archive.Entries {
+		f := files[i]
+		if e.Name != f.name || e.Mode != f.mode || !e.ModTime.Equal(modTime) || e.Size != uint64(len(f.content)) || e.Codec != c.ID() {
+			t.Errorf("entry %d = %+v, want %q mode %v size %d", i, e, f.name, f.mode, len(f.content))
+		}
+
+		zr, err := archive.Open(e)
+		if err != nil {
+			t.Fatalf("Open(%q) failed: %v", e.Name, err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("ReadAll(%q) failed: %v", e.Name, err)
+		}
+		if !bytes.Equal(content, f.content) {
+			t.Errorf("entry %q: unpacked %d bytes, want %d matching bytes", e.Name, len(content), len(f.content))
+		}
+	}
+}
+
+func TestSingleFileIsOneEntryArchive(t *testing.T) {
+	packed, err := vlcPack.Encode("A single-file stream.")
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	archive, err := OpenArchive(bytes.NewReader(packed), int64(len(packed)))
+	if err != nil {
+		t.Fatalf("OpenArchive() failed: %v", err)
+	}
+	if len(archive.Entries) != 1 {
+		t.Fatalf("OpenArchive() found %d entries, want 1", len(archive.Entries))
+	}
+
+	e := archive.Entries[0]
+	if e.Name != "" || e.Size != uint64(len("A single-file stream.")) {
+		t.Errorf("entry = %+v, want unnamed entry of %d bytes", e, len("A single-file stream."))
+	}
+	zr, err := archive.Open(e)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if string(content) != "A single-file stream." {
+		t.Errorf("unpacked %q, want %q", content, "A single-file stream.")
+	}
+}
+
+func TestSingleFileKeepsOriginalName(t *testing.T) {
+	var packed bytes.Buffer
+	zw := vlcPack.NewWriter(&packed)
+	zw.Header.Name = "report.md"
+	if _, err := zw.Write([]byte("# Report\n")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	archive, err := OpenArchive(bytes.NewReader(packed.Bytes()), int64(packed.Len()))
+	if err != nil {
+		t.Fatalf("OpenArchive() failed: %v", err)
+	}
+	e := archive.Entries[0]
+	if e.Name != "report.md" {
+		t.Errorf("entry name = %q, want %q", e.Name, "report.md")
+	}
+	zr, err := archive.Open(e)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if content, err := io.ReadAll(zr); err != nil || string(content) != "# Report\n" {
+		t.Errorf("unpacked %q, %v, want %q", content, err, "# Report\n")
+	}
+}
+
+func TestOpenArchiveRejectsBadFooter(t *testing.T) {
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	data := buf.Bytes()
+	data[len(data)-container.FooterSize] ^= 0xFF
+	if _, err := OpenArchive(bytes.NewReader(data), int64(len(data))); !errors.Is(err, container.ErrBadFooter) {
+		t.Errorf("OpenArchive() error = %v, want %v", err, container.ErrBadFooter)
+	}
+}
@@ -0,0 +1,240 @@
+package vlcUnpack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/fileMetadata"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/outputFile"
+)
+
+// ExtractOptions controls how much of the recorded metadata Extract restores and
+// what happens to files that already exist.
+type ExtractOptions struct {
+	// Overwrite says what happens when a file or symlink to be extracted already
+	// exists. Existing directories are always reused.
+	Overwrite outputFile.Policy
+
+	// PreservePerms restores permission bits exactly, including setuid, setgid
+	// and sticky bits. Otherwise the umask is applied, as for new files.
+	PreservePerms bool
+
+	// PreserveOwner restores the owner and group, by name where the names exist
+	// on this system and by numeric ID otherwise. This usually requires root.
+	PreserveOwner bool
+
+	// NoModTime leaves modification and access times at the time of extraction.
+	NoModTime bool
+
+	// Xattrs restores recorded extended attributes.
+	Xattrs bool
+}
+
+// Extract recreates every entry of the archive under the directory dest, which is
+// created if needed, restoring metadata as selected by opts. Symlinks are created
+// after every file has been written, so that no file is written through a link
+// from the archive. Entries with an empty or unsafe name, such as one leaving
+// dest, are rejected, and so are entries whose path below dest crosses a
+// symlink, whether one from the archive or one already there. Files skipped
+// under opts.Overwrite are reported and left alone.
+func (a *Archive) Extract(dest string, opts ExtractOptions) error {
+	var dirs, links []container.Entry
+
+	for _, e := range a.Entries {
+		if !fs.ValidPath(e.Name) || e.Name == "." {
+			return fmt.Errorf("entry %q: unsafe name", e.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(e.Name))
+
+		dir := path.Dir(e.Name)
+		if e.Mode.IsDir() {
+			dir = e.Name
+		}
+		if err := checkNoSymlinks(dest, dir); err != nil {
+			return fmt.Errorf("unpack %s: %w", e.Name, err)
+		}
+
+		switch {
+		case e.Mode.IsDir():
+			// Owner-writable until finished, so read-only directories can be filled.
+			if err := os.MkdirAll(target, 0700|e.Mode.Perm()); err != nil {
+				return fmt.Errorf("create directory: %w", err)
+			}
+			dirs = append(dirs, e)
+		case e.Mode&fs.ModeSymlink != 0:
+			links = append(links, e)
+		default:
+			if err := skipped(a.extractFile(e, target, opts)); err != nil {
+				return fmt.Errorf("unpack %s: %w", e.Name, err)
+			}
+		}
+	}
+
+	for _, e := range links {
+		// An earlier link may have replaced a directory on the way.
+		if err := checkNoSymlinks(dest, path.Dir(e.Name)); err != nil {
+			return fmt.Errorf("unpack %s: %w", e.Name, err)
+		}
+		if err := skipped(a.extractSymlink(e, filepath.Join(dest, filepath.FromSlash(e.Name)), opts)); err != nil {
+			return fmt.Errorf("unpack %s: %w", e.Name, err)
+		}
+	}
+
+	// Children change their directory's modification time, so directories are
+	// finished last, deepest first.
+	for _, e := range slices.Backward(dirs) {
+		if err := applyMetadata(filepath.Join(dest, filepath.FromSlash(e.Name)), e, opts); err != nil {
+			return fmt.Errorf("unpack %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkNoSymlinks returns an error if any component of the slash-separated
+// directory dir below dest is a symlink, checking each with os.Lstat. Writing
+// below such a component would follow the link, possibly out of dest.
+// Components that do not exist yet are created as real directories.
+func checkNoSymlinks(dest, dir string) error {
+	if dir == "." {
+		return nil
+	}
+
+	current := dest
+	for _, part := range strings.Split(dir, "/") {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("path crosses symlink %s", current)
+		}
+	}
+	return nil
+}
+
+// skipped reports an output skipped under outputFile.Policy.NoClobber as a warning
+// and returns nil for it; other errors are returned unchanged.
+func skipped(err error) error {
+	if errors.Is(err, outputFile.ErrSkipped) {
+		log.Printf("Warning: %v", err)
+		return nil
+	}
+	return err
+}
+
+// extractFile writes the contents of e to target, or where opts.Overwrite puts it
+// if target exists, creating its parent directories, and applies its metadata.
+func (a *Archive) extractFile(e container.Entry, target string, opts ExtractOptions) error {
+	zr, err := a.Open(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	output, err := outputFile.Create(target, 0600, opts.Overwrite)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer output.Abort()
+	target = output.Name()
+	if _, err := io.Copy(output, zr); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if err := output.Commit(); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+	return applyMetadata(target, e, opts)
+}
+
+// extractSymlink creates target as a symlink to the link target stored in e. An
+// existing file is handled as opts.Overwrite says, but a directory is never
+// replaced. Only the owner of a symlink is restored.
+func (a *Archive) extractSymlink(e container.Entry, target string, opts ExtractOptions) error {
+	zr, err := a.Open(e)
+	if err != nil {
+		return err
+	}
+	link, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	if target, err = outputFile.Path(target, opts.Overwrite); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(target); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%s exists and is a directory", target)
+		}
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Symlink(string(link), target); err != nil {
+		return err
+	}
+
+	if opts.PreserveOwner {
+		if err := fileMetadata.Chown(target, e); err != nil {
+			return fmt.Errorf("set owner: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyMetadata restores the metadata of e selected by opts on the file or
+// directory target. The owner is set first, since changing it clears setuid and
+// setgid bits, and the times last, since the other changes may update them.
+func applyMetadata(target string, e container.Entry, opts ExtractOptions) error {
+	if opts.PreserveOwner {
+		if err := fileMetadata.Chown(target, e); err != nil {
+			return fmt.Errorf("set owner: %w", err)
+		}
+	}
+
+	mode := e.Mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+	if !opts.PreservePerms {
+		mode = e.Mode.Perm() &^ fileMetadata.Umask()
+	}
+	if err := os.Chmod(target, mode); err != nil {
+		return fmt.Errorf("set permissions: %w", err)
+	}
+
+	if opts.Xattrs {
+		if err := fileMetadata.SetXattrs(target, e); err != nil {
+			return err
+		}
+	}
+
+	if opts.NoModTime || e.ModTime.IsZero() {
+		return nil
+	}
+	atime := e.AccessTime
+	if atime.IsZero() {
+		atime = e.ModTime
+	}
+	if err := os.Chtimes(target, atime, e.ModTime); err != nil {
+		return fmt.Errorf("set modification time: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package vlcUnpack
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/fileMetadata"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/outputFile"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
+)
+
+func TestExtractRecreatesTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "project")
+	files := map[string]string{
+		"README":           "top level\n",
+		"src/main.go":      "package main\n",
+		"src/util/util.go": "package util\n",
+		"src/debug.tmp":    "ignored by .vlcignore\n",
+		"build/out.bin":    "excluded by option\n",
+		".vlcignore":       "# scratch files\n*.tmp\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(src, "empty"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("src/main.go", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	if err := aw.AddPath(src, vlcPack.AddOptions{Exclude: []string{"build/"}}); err != nil {
+		t.Fatalf("AddPath() failed: %v", err)
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	archive, err := OpenArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenArchive() failed: %v", err)
+	}
+	dest := t.TempDir()
+	if err := archive.Extract(dest, ExtractOptions{PreservePerms: true}); err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(dest, "project", filepath.FromSlash(name)))
+		switch name {
+		case "src/debug.tmp", "build/out.bin":
+			if !os.IsNotExist(err) {
+				t.Errorf("%s was unpacked, want it left out", name)
+			}
+		default:
+			if err != nil || string(got) != content {
+				t.Errorf("%s = %q, %v, want %q", name, got, err, content)
+			}
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "project", "empty"))
+	if err != nil || !info.IsDir() || info.Mode().Perm() != 0750 {
+		t.Errorf("empty directory = %v, %v, want directory with mode 0750", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "project", "src", "main.go")); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("src/main.go mode = %v, %v, want 0640", info, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "project", "link")); err != nil || link != "src/main.go" {
+		t.Errorf("link = %q, %v, want symlink to src/main.go", link, err)
+	}
+}
+
+func TestExtractRejectsUnsafeNames(t *testing.T) {
+	for _, name := range []string{"../outside", "/etc/passwd", ""} {
+		archive := &Archive{Entries: container.Directory{{Name: name, Mode: fs.ModeDir | 0755}}}
+		if err := archive.Extract(t.TempDir(), ExtractOptions{}); err == nil {
+			t.Errorf("Extract() of entry %q succeeded, want error", name)
+		}
+	}
+}
+
+func TestExtractMetadataOptions(t *testing.T) {
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	w, err := aw.Create(container.Entry{Name: "run.sh", Mode: fs.ModeSetgid | 0777, ModTime: modTime})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if _, err := w.Write([]byte("#!/bin/sh\n")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	archive, err := OpenArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenArchive() failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		opts        ExtractOptions
+		wantMode    fs.FileMode
+		wantModTime bool
+	}{
+		{"defaults", ExtractOptions{}, 0777 &^ fileMetadata.Umask(), true},
+		{"preserve perms", ExtractOptions{PreservePerms: true}, fs.ModeSetgid | 0777, true},
+		{"no mtime", ExtractOptions{NoModTime: true}, 0777 &^ fileMetadata.Umask(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+			if err := archive.Extract(dest, tt.opts); err != nil {
+				t.Fatalf("Extract() failed: %v", err)
+			}
+			info, err := os.Stat(filepath.Join(dest, "run.sh"))
+			if err != nil {
+				t.Fatalf("Stat() failed: %v", err)
+			}
+			if got := info.Mode() &^ fs.ModeType; got != tt.wantMode {
+				t.Errorf("mode = %v, want %v", got, tt.wantMode)
+			}
+			if got := info.ModTime().Equal(modTime); got != tt.wantModTime {
+				t.Errorf("modification time = %v, restored = %v, want %v", info.ModTime(), got, tt.wantModTime)
+			}
+		})
+	}
+}
+
+func TestExtractRefusesPathsThroughSymlinks(t *testing.T) {
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+
+	type entry struct {
+		name    string
+		mode    fs.FileMode
+		content string
+	}
+	tests := []struct {
+		name    string
+		entries []entry
+		opts    ExtractOptions
+		victim  string // file in the outside directory, "" if none
+		want    string // path below the outside directory that must not exist
+	}{
+		{
+			name: "link below earlier link",
+			entries: []entry{
+				{name: "x", mode: fs.ModeSymlink | 0777, content: "OUTSIDE"},
+				{name: "x/evil", mode: fs.ModeSymlink | 0777, content: "/etc/passwd"},
+			},
+			want: "evil",
+		},
+		{
+			name: "forced link below earlier link",
+			entries: []entry{
+				{name: "x", mode: fs.ModeSymlink | 0777, content: "OUTSIDE"},
+				{name: "x/victim", mode: fs.ModeSymlink | 0777, content: "/etc/passwd"},
+			},
+			opts:   ExtractOptions{Overwrite: outputFile.Policy{Force: true}},
+			victim: "victim",
+		},
+		{
+			name:    "file below existing link",
+			entries: []entry{{name: "pre/file", mode: 0644, content: "data"}},
+			want:    "file",
+		},
+		{
+			name:    "directory at existing link",
+			entries: []entry{{name: "pre", mode: fs.ModeDir | 0700}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outside := t.TempDir()
+			if tt.victim != "" {
+				if err := os.WriteFile(filepath.Join(outside, tt.victim), []byte("keep"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dest := t.TempDir()
+			if err := os.Symlink(outside, filepath.Join(dest, "pre")); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			aw := vlcPack.NewArchiveWriter(&buf, c)
+			for _, e := range tt.entries {
+				w, err := aw.Create(container.Entry{Name: e.name, Mode: e.mode})
+				if err != nil {
+					t.Fatalf("Create() failed: %v", err)
+				}
+				content := strings.ReplaceAll(e.content, "OUTSIDE", outside)
+				if _, err := w.Write([]byte(content)); err != nil {
+					t.Fatalf("Write() failed: %v", err)
+				}
+			}
+			if err := aw.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+			archive, err := OpenArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("OpenArchive() failed: %v", err)
+			}
+
+			if err := archive.Extract(dest, tt.opts); err == nil {
+				t.Error("Extract() succeeded, want error")
+			}
+			if tt.want != "" {
+				if _, err := os.Lstat(filepath.Join(outside, tt.want)); !os.IsNotExist(err) {
+					t.Errorf("Extract() created %s outside dest", tt.want)
+				}
+			}
+			if tt.victim != "" {
+				if got, err := os.ReadFile(filepath.Join(outside, tt.victim)); err != nil || string(got) != "keep" {
+					t.Errorf("%s outside dest = %q, %v, want it left alone", tt.victim, got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package vlcUnpack
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+)
+
+// Reader is an io.Reader that unpacks a `.vlc` stream. Blocks are read and decoded
+// one at a time as the caller consumes data, so memory use does not grow with the
+// size of the stream. Every block is checked against its checksum as it is
+// decoded, and the whole data against the trailer at the end; mismatches are
+// reported as container.ErrChecksum. Like gzip.Reader, the parsed Header is
+// available as soon as NewReader returns.
+type Reader struct {
+	Header container.Header
+
+	r       *bufio.Reader
+	codec   codec.Codec
+	entry   *container.Entry // directory entry the trailer must also match, if any
+	packed  []byte
+	decoded bytes.Buffer
+	length  uint64
+	crc     hash.Hash32
+	digest  hash.Hash // nil unless the header has container.FlagSHA256
+	err     error
+}
+
+// NewReader reads and validates the header from r and returns a Reader that
+// yields the unpacked data. Returns an error for invalid headers or unknown codecs.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := container.ReadHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := codec.ByID(header.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReader(br, header, c), nil
+}
+
+// newPayloadReader returns a Reader that unpacks a payload without a header from
+// r with the given codec, for the entry e of an archive with the given header.
+// The trailer must also match the size and checksums recorded in e.
+func newPayloadReader(r io.Reader, c codec.Codec, header container.Header, e container.Entry) *Reader {
+	z := newReader(bufio.NewReader(r), header, c)
+	z.entry = &e
+	return z
+}
+
+// newReader returns a Reader for the payload in br described by header.
+func newReader(br *bufio.Reader, header container.Header, c codec.Codec) *Reader {
+	z := &Reader{Header: header, r: br, codec: c, crc: container.NewChecksum()}
+	if header.Flags&container.FlagSHA256 != 0 {
+		z.digest = sha256.New()
+	}
+	return z
+}
+
+// Read fills p with unpacked data, decoding the next block whenever the previous
+// one has been consumed. It returns io.EOF after the trailer has been read and
+// the total length and checksums verified.
+func (z *Reader) Read(p []byte) (int, error) {
+	for z.decoded.Len() == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.err = z.nextBlock()
+	}
+	return z.decoded.Read(p)
+}
+
+// nextBlock reads and decodes the next block into z.decoded. At the end of the
+// payload it checks the trailer and returns io.EOF.
+func (z *Reader) nextBlock() error {
+	blockHeader, err := container.ReadBlockHeader(z.r)
+	if err != nil {
+		return err
+	}
+
+	if blockHeader.RawLength == 0 {
+		trailer, err := container.ReadTrailer(z.r, z.Header)
+		if err != nil {
+			return err
+		}
+		if err := z.checkTrailer(trailer); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	if blockHeader.RawLength > container.BlockSize {
+		return fmt.Errorf("block after %d bytes: length %d exceeds %d", z.length, blockHeader.RawLength, container.BlockSize)
+	}
+
+	if uint64(cap(z.packed)) < blockHeader.PackedLength {
+		z.packed = make([]byte, blockHeader.PackedLength)
+	}
+	z.packed = z.packed[:blockHeader.PackedLength]
+	if _, err := io.ReadFull(z.r, z.packed); err != nil {
+		return fmt.Errorf("%w: block after %d bytes: %v", container.ErrTruncated, z.length, err)
+	}
+
+	// Decoding stops as soon as the block grows past its recorded length, so a
+	// corrupt or crafted block cannot expand beyond container.BlockSize.
+	z.decoded.Reset()
+	limited := chunks.LimitWriter(&z.decoded, int64(blockHeader.RawLength))
+	if err := z.codec.Decode(limited, bytes.NewReader(z.packed)); err != nil {
+		if errors.Is(err, chunks.ErrLimit) {
+			return fmt.Errorf("block length mismatch: header says %d bytes: %w", blockHeader.RawLength, err)
+		}
+		return fmt.Errorf("%s decode block after %d bytes: %w", z.codec.Name(), z.length, err)
+	}
+	if uint64(z.decoded.Len()) != blockHeader.RawLength {
+		return fmt.Errorf("block length mismatch: header says %d bytes, decoded %d", blockHeader.RawLength, z.decoded.Len())
+	}
+	if crc := container.Checksum(z.decoded.Bytes()); crc != blockHeader.CRC32C {
+		return fmt.Errorf("%w: block after %d bytes: CRC32C %08x, want %08x", container.ErrChecksum, z.length, crc, blockHeader.CRC32C)
+	}
+
+	z.crc.Write(z.decoded.Bytes())
+	if z.digest != nil {
+		z.digest.Write(z.decoded.Bytes())
+	}
+	z.length += blockHeader.RawLength
+	return nil
+}
+
+// checkTrailer compares the length and checksums of the decoded data with the
+// trailer and, for archive entries, with the directory entry.
+func (z *Reader) checkTrailer(trailer container.Trailer) error {
+	if trailer.Length != z.length {
+		return fmt.Errorf("length mismatch: trailer says %d bytes, decoded %d", trailer.Length, z.length)
+	}
+	if crc := z.crc.Sum32(); crc != trailer.CRC32C {
+		return fmt.Errorf("%w: CRC32C %08x, trailer says %08x", container.ErrChecksum, crc, trailer.CRC32C)
+	}
+	if z.digest != nil && !bytes.Equal(z.digest.Sum(nil), trailer.SHA256) {
+		return fmt.Errorf("%w: SHA-256 differs from trailer", container.ErrChecksum)
+	}
+
+	if e := z.entry; e != nil {
+		if e.Size != trailer.Length {
+			return fmt.Errorf("length mismatch: directory says %d bytes, decoded %d", e.Size, z.length)
+		}
+		if e.CRC32C != trailer.CRC32C || e.SHA256 != nil && !bytes.Equal(e.SHA256, trailer.SHA256) {
+			return fmt.Errorf("%w: directory and trailer checksums differ", container.ErrChecksum)
+		}
+	}
+	return nil
+}
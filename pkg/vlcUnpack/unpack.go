@@ -0,0 +1,166 @@
+package vlcUnpack
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/flexer2006/simpleArchiver-golang/internal/application"
+	"github.com/spf13/cobra"
+)
+
+// unpackOptions holds the flags of UnpackCmd.
+type unpackOptions struct {
+	// extract holds the metadata and overwrite flags.
+	extract ExtractOptions
+
+	// dest is the directory to extract into. Set by the --dest flag; when empty
+	// the archive's own directory is used.
+	dest string
+
+	// toStdout writes the contents of every file to standard output instead of
+	// extracting them. Set by the --stdout flag.
+	toStdout bool
+}
+
+// unpackOpts holds the flags given to UnpackCmd.
+var unpackOpts unpackOptions
+
+// UnpackCmd is the Cobra command for unpacking every file of an archive.
+// Usage: unpack [archive_path | -]
+// Short: Unpack all files from an archive.
+var UnpackCmd = &cobra.Command{
+	Use:   "unpack [archive_path | -]",
+	Short: "Unpack all files from an archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		application.HandlePanic(func() {
+			err := application.HandleError(func() error {
+				archivePath, err := application.InputPath(args)
+				if err != nil {
+					return err
+				}
+				return unpackArchive(archivePath, unpackOpts)
+			})
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		})
+	},
+}
+
+// unpackArchive recreates the tree stored in the archive at the given path under
+// the --dest directory, or under the archive's directory when that is empty. The
+// unnamed entry of a single-file stream is written where vlcUnpack would put it.
+// opts selects the metadata restored. The path application.StdioPath reads the
+// archive from standard input into the current directory, and --stdout writes the
+// contents of its files to standard output instead. Returns an error if any step
+// fails.
+func unpackArchive(archivePath string, opts unpackOptions) error {
+	file, err := openArchiveFile(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("Warning: failed to close file: %v", closeErr)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat file: %w", err)
+	}
+	archive, err := OpenArchive(file, info.Size())
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+
+	if opts.toStdout {
+		return catArchive(os.Stdout, archive)
+	}
+
+	dest := opts.dest
+	if dest == "" {
+		dest = filepath.Dir(archivePath)
+	}
+	for i, e := range archive.Entries {
+		if e.Name == "" {
+			archive.Entries[i].Name = SingleFileName(archivePath)
+		}
+	}
+	if err := archive.Extract(dest, opts.extract); err != nil {
+		return err
+	}
+
+	log.Printf("%d entries successfully unpacked from %s to %s", len(archive.Entries), archivePath, dest)
+	return nil
+}
+
+// openArchiveFile opens the archive at archivePath for random access. Since the
+// central directory is at the end, an archive read from standard input is first
+// copied to a temporary file, which is removed again when the returned file is
+// closed.
+func openArchiveFile(archivePath string) (*os.File, error) {
+	if archivePath != application.StdioPath {
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("open file: %w", err)
+		}
+		return file, nil
+	}
+
+	spool, err := os.CreateTemp("", "vlc-stdin-*")
+	if err != nil {
+		return nil, fmt.Errorf("buffer standard input: %w", err)
+	}
+	// Unlinked straight away, the file lives on until it is closed.
+	if err := os.Remove(spool.Name()); err != nil {
+		log.Printf("Warning: failed to remove %s: %v", spool.Name(), err)
+	}
+	if _, err := io.Copy(spool, os.Stdin); err != nil {
+		_ = spool.Close()
+		return nil, fmt.Errorf("buffer standard input: %w", err)
+	}
+	return spool, nil
+}
+
+// catArchive writes the contents of every regular file in archive to w, one
+// after the other, like `tar -O`.
+func catArchive(w io.Writer, archive *Archive) error {
+	for _, e := range archive.Entries {
+		if !e.Mode.IsRegular() {
+			continue
+		}
+		zr, err := archive.Open(e)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, zr); err != nil {
+			return fmt.Errorf("unpack %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// init registers the UnpackCmd flags during package initialization. The command
+// itself is added to the root command by cmds.InitCommands.
+func init() {
+	application.HandlePanic(func() {
+		flags := UnpackCmd.Flags()
+		flags.StringVarP(&unpackOpts.dest, "dest", "d", "",
+			"directory to unpack into (default: the archive's directory)")
+		flags.BoolVar(&unpackOpts.extract.PreservePerms, "preserve-perms", false,
+			"restore permissions exactly, ignoring the umask")
+		flags.BoolVar(&unpackOpts.extract.PreserveOwner, "preserve-owner", false,
+			"restore file owners and groups (usually requires root)")
+		flags.BoolVar(&unpackOpts.extract.NoModTime, "no-mtime", false,
+			"do not restore modification and access times")
+		flags.BoolVar(&unpackOpts.extract.Xattrs, "xattrs", false,
+			"restore recorded extended attributes")
+		flags.BoolVarP(&unpackOpts.toStdout, "stdout", "c", false,
+			"write the contents of every file to standard output")
+		unpackOpts.extract.Overwrite.AddFlags(UnpackCmd)
+	})
+}
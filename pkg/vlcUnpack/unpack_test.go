@@ -0,0 +1,54 @@
+package vlcUnpack
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
+)
+
+func TestCatArchiveConcatenatesRegularFiles(t *testing.T) {
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	for _, e := range []struct {
+		name    string
+		mode    fs.FileMode
+		content string
+	}{
+		{"dir", fs.ModeDir | 0755, ""},
+		{"dir/first.txt", 0644, "first\n"},
+		{"dir/link", fs.ModeSymlink | 0777, "first.txt"},
+		{"dir/second.txt", 0644, "second\n"},
+	} {
+		w, err := aw.Create(container.Entry{Name: e.name, Mode: e.mode})
+		if err != nil {
+			t.Fatalf("Create(%q) failed: %v", e.name, err)
+		}
+		if _, err := w.Write([]byte(e.content)); err != nil {
+			t.Fatalf("Write(%q) failed: %v", e.name, err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	archive, err := OpenArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenArchive() failed: %v", err)
+	}
+	var out bytes.Buffer
+	if err := catArchive(&out, archive); err != nil {
+		t.Fatalf("catArchive() failed: %v", err)
+	}
+	if got, want := out.String(), "first\nsecond\n"; got != want {
+		t.Errorf("catArchive() wrote %q, want %q", got, want)
+	}
+}
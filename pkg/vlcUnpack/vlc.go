@@ -1,20 +1,24 @@
-// Package vlcUnpack provides functionality for unpacking files encoded with variable-length code (VLC).
-// It reads a `.vlc` file, decodes its contents, and writes the decoded text to a new `.txt` file.
+// Package vlcUnpack provides functionality for unpacking files encoded with
+// variable-length code (VLC). It reads a `.vlc` file of packed bytes (or hex text
+// with --text), decodes its contents, and writes the decoded data to a file under
+// its original name (or a new `.txt` file for streams that do not record one), or
+// reads standard input and writes standard output in shell pipelines. The unpack
+// command restores every file of an archive written by `pack`.
 package vlcUnpack
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
-	"unicode"
 
 	"github.com/flexer2006/simpleArchiver-golang/internal/application"
 	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
-	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
-	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/outputFile"
 	"github.com/spf13/cobra"
 )
 
@@ -23,19 +27,46 @@ const (
 	unpackedExtension = "txt"
 )
 
+// vlcUnpackOptions holds the flags of VlcUnpackCmd.
+type vlcUnpackOptions struct {
+	// text selects reading the space-separated hex form written by
+	// `vlcPack --text`. Set by the --text flag.
+	text bool
+
+	// toStdout writes the unpacked data to standard output instead of a file.
+	// Set by the --stdout flag.
+	toStdout bool
+
+	// output is the file to write, "-" for standard output. Set by the --output
+	// flag; when empty the name comes from the header or generateOutputPath.
+	output string
+
+	// outputDir is the directory to write to instead of the input's directory.
+	// Set by the --output-dir flag.
+	outputDir string
+
+	// overwrite says what happens to an existing output file. Set by the
+	// --force, --no-clobber and --suffix flags.
+	overwrite outputFile.Policy
+}
+
+// vlcUnpackOpts holds the flags given to VlcUnpackCmd.
+var vlcUnpackOpts vlcUnpackOptions
+
 // VlcUnpackCmd is the Cobra command for unpacking files encoded with variable-length code.
-// Usage: vlcUnpack [file_path]
+// Usage: vlcUnpack [file_path | -]
 // Short: Unpack file using variable-length code.
 var VlcUnpackCmd = &cobra.Command{
-	Use:   "vlcUnpack [file_path]",
+	Use:   "vlcUnpack [file_path | -]",
 	Short: "Unpack file using variable-length code",
 	Run: func(cmd *cobra.Command, args []string) {
 		application.HandlePanic(func() {
 			err := application.HandleError(func() error {
-				if len(args) == 0 || args[0] == "" {
-					return application.ErrEmptyPath
+				filePath, err := application.InputPath(args)
+				if err != nil {
+					return err
 				}
-				return unpack(args[0])
+				return unpack(filePath, vlcUnpackOpts)
 			})
 			if err != nil {
 				log.Fatalf("Error: %v", err)
@@ -44,48 +75,104 @@ var VlcUnpackCmd = &cobra.Command{
 	},
 }
 
-// unpack reads the file at the given path, decodes its contents using variable-length code,
-// and writes the decoded text to a new file with a `.txt` extension.
-// Returns an error if any step fails.
-func unpack(filePath string) error {
-	file, err := os.Open(filePath)
+// unpack streams the file at the given path through a Reader, which decodes it
+// with the codec named in its header, and writes the decoded data under the
+// original file name to the file chosen by unpackedPath, following the --force,
+// --no-clobber or --suffix policy if it exists. The path application.StdioPath
+// reads standard input. Returns an error if any step fails.
+func unpack(filePath string, opts vlcUnpackOptions) error {
+	var input io.Reader = os.Stdin
+	var info os.FileInfo
+	if filePath != application.StdioPath {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("open file: %w", err)
+		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil {
+				log.Printf("Warning: failed to close file: %v", closeErr)
+			}
+		}()
+
+		if info, err = file.Stat(); err != nil {
+			return fmt.Errorf("stat file: %w", err)
+		}
+		input = file
+	}
+	if opts.text {
+		input = chunks.NewHexReader(input)
+	}
+
+	zr, err := NewReader(input)
 	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+		return fmt.Errorf("decode: %w", err)
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			log.Printf("Warning: failed to close file: %v", closeErr)
+
+	target := unpackedPath(filePath, zr.Header.Name, opts)
+	if target == application.StdioPath {
+		if _, err := io.Copy(os.Stdout, zr); err != nil {
+			return fmt.Errorf("decode: %w", err)
 		}
-	}()
+		return nil
+	}
+	if info != nil && outputFile.IsInput(target, info) {
+		return fmt.Errorf("output file %s is the input file", target)
+	}
 
-	data, err := io.ReadAll(file)
+	output, err := outputFile.Create(target, 0644, opts.overwrite)
+	if errors.Is(err, outputFile.ErrSkipped) {
+		log.Printf("Warning: %v", err)
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("read file: %w", err)
+		return fmt.Errorf("create output file: %w", err)
 	}
+	defer output.Abort()
 
-	decoded, err := Decode(string(data))
-	if err != nil {
+	if _, err := io.Copy(output, zr); err != nil {
 		return fmt.Errorf("decode: %w", err)
 	}
-
-	outputPath := generateOutputPath(filePath)
-	if err := os.WriteFile(outputPath, []byte(decoded), 0644); err != nil {
+	if err := output.Commit(); err != nil {
 		return fmt.Errorf("write output file: %w", err)
 	}
 
-	log.Printf("File successfully unpacked: %s", outputPath)
+	log.Printf("File successfully unpacked: %s", output.Name())
 	return nil
 }
 
+// unpackedPath returns where unpack writes the unpacked form of filePath, whose
+// header records the original file name, if any: standard output for --stdout or
+// standard input, the --output path, or the original name in --output-dir or, by
+// default, next to the input file. Streams without a name get the one from
+// generateOutputPath.
+func unpackedPath(filePath, name string, opts vlcUnpackOptions) string {
+	switch {
+	case opts.toStdout:
+		return application.StdioPath
+	case opts.output != "":
+		return opts.output
+	case filePath == application.StdioPath:
+		return application.StdioPath
+	}
+
+	if name == "" {
+		name = filepath.Base(generateOutputPath(filePath))
+	}
+	dir := opts.outputDir
+	if dir == "" {
+		dir = filepath.Dir(filePath)
+	}
+	return filepath.Join(dir, name)
+}
+
 // generateOutputPath generates the output file path by replacing the original file's
 // extension with `.txt`.
 func generateOutputPath(path string) string {
 	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + unpackedExtension
 }
 
-// Decode converts a space-separated hexadecimal string into its original text form.
-// It validates the hex chunks, converts them to binary, and uses a decoding tree
-// to reconstruct the original text.
+// DecodeText converts a space-separated hexadecimal string, as produced by
+// vlcPack.EncodeText, into its original text form.
 //
 // Parameters:
 //   - encodedData: The space-separated hexadecimal string to decode.
@@ -93,75 +180,63 @@ func generateOutputPath(path string) string {
 // Returns:
 //   - string: The decoded text.
 //   - error: An error if decoding fails (e.g., invalid hex chunks or decoding tree issues).
-func Decode(encodedData string) (string, error) {
-	if encodedData == "" {
-		return "", nil
-	}
-
+func DecodeText(encodedData string) (string, error) {
 	// Parse the hex chunks and validate them
 	hexChunks, err := chunks.NewHexChunks(encodedData)
 	if err != nil {
 		return "", fmt.Errorf("parse hex chunks: %w", err)
 	}
 
-	// Convert hex chunks to binary
-	binaryChunks, err := hexChunks.ToBinary()
-	if err != nil {
-		return "", fmt.Errorf("convert hex to binary: %w", err)
-	}
-
-	// Join binary chunks into a single binary string
-	binaryData := binaryChunks.Join()
-
-	// Build the decoding tree from the encoding table
-	encodingTable := table.BuildEncodingTable()
-	tree, err := decodingTree.BuildDecodingTree(encodingTable)
+	// Convert hex chunks to raw bytes
+	packed, err := hexChunks.ToBytes()
 	if err != nil {
-		return "", fmt.Errorf("build decoding tree: %w", err)
+		return "", fmt.Errorf("convert hex to bytes: %w", err)
 	}
 
-	// Decode the binary data using the tree
-	decoded, err := tree.Decode(binaryData)
-	if err != nil {
-		return "", fmt.Errorf("decode binary data: %w", err)
-	}
-
-	// Restore the original case of the text
-	return restoreCase(decoded), nil
+	return Decode(packed)
 }
 
-// restoreCase processes the decoded text to restore uppercase letters.
-// Uppercase letters are prefixed with '!' in the encoded data, so this function
-// converts the next character to uppercase when '!' is encountered.
+// Decode converts a packed `.vlc` stream into its original form. It is an
+// in-memory convenience built on Reader.
 //
 // Parameters:
-//   - str: The decoded text to process.
+//   - data: The packed `.vlc` stream.
 //
 // Returns:
-//   - string: The text with uppercase letters restored.
-func restoreCase(str string) string {
-	var buf strings.Builder
-	var capitalizeNext bool
-
-	for _, r := range str {
-		if capitalizeNext && unicode.IsLetter(r) {
-			buf.WriteRune(unicode.ToUpper(r))
-			capitalizeNext = false
-		} else {
-			buf.WriteRune(r)
-		}
+//   - string: The decoded text.
+//   - error: An error if decoding fails (e.g., invalid header or unknown codec).
+func Decode(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
 
-		if r == '!' {
-			capitalizeNext = true
-		}
+	zr, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("read header: %w", err)
+	}
+
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
 	}
 
-	return buf.String()
+	return string(decoded), nil
 }
 
-// init registers the VlcUnpackCmd with the root command during package initialization.
+// init registers the VlcUnpackCmd flags during package initialization. The command
+// itself is added to the root command by cmds.InitCommands.
 func init() {
 	application.HandlePanic(func() {
-		application.RootCmd.AddCommand(VlcUnpackCmd)
+		flags := VlcUnpackCmd.Flags()
+		flags.BoolVar(&vlcUnpackOpts.text, "text", false,
+			"read packed data as space-separated hex (debugging)")
+		flags.BoolVarP(&vlcUnpackOpts.toStdout, "stdout", "c", false,
+			"write unpacked data to standard output")
+		flags.StringVarP(&vlcUnpackOpts.output, "output", "o", "",
+			"path of the unpacked file, - for standard output")
+		flags.StringVar(&vlcUnpackOpts.outputDir, "output-dir", "",
+			"directory to write the unpacked file to (default: the input's directory)")
+		VlcUnpackCmd.MarkFlagsMutuallyExclusive("stdout", "output", "output-dir")
+		vlcUnpackOpts.overwrite.AddFlags(VlcUnpackCmd)
 	})
 }
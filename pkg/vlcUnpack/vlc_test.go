@@ -1,20 +1,30 @@
 package vlcUnpack
 
 import (
-	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
 	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/chunks"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/decodingTree"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/table"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
 )
 
 func TestDecodeInvalidHexChunks(t *testing.T) {
 	invalidHexData := "xyz123"
-	_, err := Decode(invalidHexData)
+	_, err := DecodeText(invalidHexData)
 	if err == nil {
 		t.Errorf("Expected an error for invalid hex data, but got nil")
 	}
 }
 
 func TestDecodeEmptyData(t *testing.T) {
-	result, err := Decode("")
+	result, err := Decode(nil)
 	if err != nil {
 		t.Errorf("Expected no error for empty data, but got %v", err)
 	}
@@ -24,22 +34,171 @@ func TestDecodeEmptyData(t *testing.T) {
 }
 
 func TestBuildDecodingTree(t *testing.T) {
-	encodingTable := map[rune]string{
-		'a': "00",
-		'b': "01",
-		'c': "10",
+	codes := table.CodeTable{
+		'a': {Bits: 0b00, Length: 2},
+		'b': {Bits: 0b01, Length: 2},
+		'c': {Bits: 0b10, Length: 2},
+	}
+
+	tree, err := decodingTree.BuildDecodingTree(codes)
+	if err != nil {
+		t.Fatalf("BuildDecodingTree() failed: %v", err)
+	}
+
+	br := chunks.NewBitReader(bytes.NewReader([]byte{0b00011000}))
+	for _, want := range "abc" {
+		got, err := tree.ReadSymbol(br)
+		if err != nil {
+			t.Fatalf("ReadSymbol() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("ReadSymbol() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestDecodePackedBytes(t *testing.T) {
+	header, err := container.NewHeader(codec.IDVLC).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+
+	// One block of two bytes holding a two-symbol VLC stream: "ta" encodes as
+	// 0010 0011, which fills exactly one byte. Then the end marker and trailer.
+	crc := binary.LittleEndian.AppendUint32(nil, container.Checksum([]byte("ta")))
+	data := append(header, 0x02, 0x02)
+	data = append(data, crc...)
+	data = append(data, 0x02, 0x23, 0x00)
+	data = append(data, 2, 0, 0, 0, 0, 0, 0, 0)
+	data = append(data, crc...)
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if decoded != "ta" {
+		t.Errorf("Decode() result = %v, want ta", decoded)
+	}
+
+	decoded, err = DecodeText(chunks.NewHexChunksFromBytes(data).ToString())
+	if err != nil {
+		t.Fatalf("DecodeText() failed: %v", err)
+	}
+	if decoded != "ta" {
+		t.Errorf("DecodeText() result = %v, want ta", decoded)
+	}
+}
+
+func TestDecodeRejectsUnknownVersion(t *testing.T) {
+	header, err := container.Header{Version: container.Version + 1}.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+
+	_, err = Decode(header)
+	if !errors.Is(err, container.ErrUnsupportedVersion) {
+		t.Errorf("Decode() error = %v, want %v", err, container.ErrUnsupportedVersion)
+	}
+}
+
+func TestDecodeRejectsUnknownCodec(t *testing.T) {
+	header, err := container.NewHeader(255).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+
+	_, err = Decode(header)
+	if !errors.Is(err, codec.ErrUnknownCodec) {
+		t.Errorf("Decode() error = %v, want %v", err, codec.ErrUnknownCodec)
 	}
+}
+
+func TestDecodeRejectsTruncatedStream(t *testing.T) {
+	packed, err := vlcPack.Encode("streams end with a trailer")
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	for _, cut := range []int{1, container.TrailerSize, container.TrailerSize + 1, len(packed) / 2} {
+		if _, err := Decode(packed[:len(packed)-cut]); !errors.Is(err, container.ErrTruncated) {
+			t.Errorf("Decode() with %d bytes cut: error = %v, want %v", cut, err, container.ErrTruncated)
+		}
+	}
+}
+
+func TestDecodeDetectsCorruption(t *testing.T) {
+	input := bytes.Repeat([]byte("Every block carries a checksum.\n"), 100)
+	c, err := codec.Lookup("huffman")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	packed, err := vlcPack.EncodeWith(c, input)
+	if err != nil {
+		t.Fatalf("EncodeWith() failed: %v", err)
+	}
+
+	// Flip one bit in each byte of the packed payload in turn. Every change must
+	// either fail to decode or be caught by a checksum; none may yield wrong data.
+	for i := container.HeaderSize; i < len(packed); i++ {
+		corrupt := bytes.Clone(packed)
+		corrupt[i] ^= 0x10
+		decoded, err := Decode(corrupt)
+		if err == nil {
+			t.Errorf("flipping a bit of byte %d went undetected (decoded %d bytes)", i, len(decoded))
+		}
+	}
+}
+
+func TestReaderStreamsMultipleBlocks(t *testing.T) {
+	input := bytes.Repeat([]byte("Several blocks of text.\n"), 3*container.BlockSize/24)
 
-	tree, err := decodingTree.BuildDecodingTree(encodingTable)
+	var packed bytes.Buffer
+	zw := vlcPack.NewWriter(&packed)
+	if _, err := zw.Write(input); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	zr, err := NewReader(&packed)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Errorf("decoded %d bytes, want %d matching bytes", len(decoded), len(input))
+	}
+}
+
+func TestReaderStopsAtBlockLength(t *testing.T) {
+	c, err := codec.Lookup("lz77")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	var packed bytes.Buffer
+	if err := c.Encode(&packed, bytes.NewReader(make([]byte, container.BlockSize))); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	// A block whose header understates what its packed bytes expand to.
+	stream, err := container.NewHeader(c.ID()).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+	block, err := container.BlockHeader{RawLength: 16, PackedLength: uint64(packed.Len())}.MarshalBinary()
 	if err != nil {
-		t.Errorf("BuildDecodingTree() failed: %v", err)
+		t.Fatalf("MarshalBinary() failed: %v", err)
 	}
+	stream = append(append(stream, block...), packed.Bytes()...)
 
-	decoded, err := tree.Decode("000110")
+	zr, err := NewReader(bytes.NewReader(stream))
 	if err != nil {
-		t.Errorf("Decode() failed: %v", err)
+		t.Fatalf("NewReader() failed: %v", err)
 	}
-	if decoded != "abc" {
-		t.Errorf("Decode() result = %v, want abc", decoded)
+	if _, err := io.ReadAll(zr); !errors.Is(err, chunks.ErrLimit) {
+		t.Errorf("ReadAll() error = %v, want %v", err, chunks.ErrLimit)
 	}
 }
@@ -0,0 +1,70 @@
+package vlcVerify
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flexer2006/simpleArchiver-golang/pkg/codec"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/container"
+	"github.com/flexer2006/simpleArchiver-golang/pkg/vlcPack"
+)
+
+func TestVerifyReportsDamagedEntries(t *testing.T) {
+	c, err := codec.Lookup(codec.Default)
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	aw := vlcPack.NewArchiveWriter(&buf, c)
+	aw.SHA256 = true
+	for _, name := range []string{"first.txt", "second.txt"} {
+		w, err := aw.Create(container.Entry{Name: name, Mode: 0644})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		if _, err := w.Write(bytes.Repeat([]byte(name+" contents\n"), 50)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.vlc")
+	if err := os.WriteFile(good, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := verify(&out, []string{good}); err != nil {
+		t.Fatalf("verify() of an intact archive failed: %v\n%s", err, out.String())
+	}
+
+	// Damage a byte inside the payload of the second entry, just before the directory.
+	damaged := bytes.Clone(buf.Bytes())
+	damaged[len(damaged)-150] ^= 0x01
+	bad := filepath.Join(dir, "bad.vlc")
+	if err := os.WriteFile(bad, damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	err = verify(&out, []string{bad})
+	if !errors.Is(err, ErrDamaged) {
+		t.Fatalf("verify() error = %v, want %v", err, ErrDamaged)
+	}
+	if !strings.Contains(out.String(), "OK      first.txt") || !strings.Contains(out.String(), "FAILED  second.txt") {
+		t.Errorf("verify() output does not single out second.txt:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := verify(&out, []string{filepath.Join(dir, "missing.vlc")}); !errors.Is(err, ErrDamaged) {
+		t.Errorf("verify() of a missing archive: error = %v, want %v", err, ErrDamaged)
+	}
+}